@@ -1,3 +1,7 @@
 module 01
 
 go 1.25
+
+require aoc v0.0.0
+
+replace aoc => ../aoc
@@ -1,47 +1,64 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"log"
-	"os"
+	"math/rand/v2"
 	"strconv"
+
+	"aoc/input"
+	"aoc/runner"
 )
 
 const minDial = 0
 const MaxDial = 99
+const startDial = 50
 
-func readFile(filename string) []string {
-	var result []string
+// dialSettings describe the safe's dial, which runs from minDial to max and starts at start.
+// They default to the puzzle's dial and can be changed with the dial_max and dial_start options.
+type dialSettings struct {
+	max   int
+	start int
+}
 
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
+func newDialSettings(options *runner.Options) dialSettings {
+	settings := dialSettings{
+		max:   options.Int("dial_max", MaxDial),
+		start: options.Int("dial_start", startDial),
 	}
-	defer file.Close()
-
-	// Create a new scanner to read the file line by line
-	scanner := bufio.NewScanner(file)
-
-	// Loop through the file and read each line
-	for scanner.Scan() {
-		line := scanner.Text() // Get the line as a string
-		result = append(result, line)
+	if settings.max <= minDial || settings.start < minDial || settings.start > settings.max {
+		log.Fatalf("invalid dial from %d to %d starting at %d", minDial, settings.max, settings.start)
 	}
+	return settings
+}
 
-	// Check for errors during the scan
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("error reading file: %s", err)
-	}
+// size is the number of positions on the dial.
+func (d dialSettings) size() int {
+	return d.max - minDial + 1
+}
 
-	return result
+// readFile streams the rotations of the input one line at a time.
+func readFile(filename string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		lines, linesErr := input.Lines(filename)
+		for line := range lines {
+			if !yield(line) {
+				return
+			}
+		}
+		if err := linesErr(); err != nil {
+			log.Fatalf("error reading file: %s", err)
+		}
+	}
 }
 
-func calculateOldPassword(data []string) {
-	dial := 50
+func calculateOldPassword(w io.Writer, data iter.Seq[string], settings dialSettings) int {
+	dial := settings.start
 	zeroCounter := 0
 
-	for _, line := range data {
+	for line := range data {
 
 		direction := string(line[0])
 		number, err := strconv.Atoi(line[1:])
@@ -53,15 +70,15 @@ func calculateOldPassword(data []string) {
 		if direction == "R" {
 			dial += number
 
-			for dial > MaxDial {
-				dial = dial - MaxDial - 1
+			for dial > settings.max {
+				dial -= settings.size()
 			}
 
 		} else if direction == "L" {
 			dial -= number
 
 			for dial < minDial {
-				dial = MaxDial + dial + 1
+				dial += settings.size()
 			}
 		} else {
 			log.Fatalf("invalid direction: %s", direction)
@@ -73,15 +90,16 @@ func calculateOldPassword(data []string) {
 
 	}
 
-	fmt.Println("Old password: ", zeroCounter)
+	fmt.Fprintln(w, "Old password: ", zeroCounter)
 
+	return zeroCounter
 }
 
-func calculateNewPassword(data []string) {
-	dial := 50
+func calculateNewPassword(w io.Writer, data iter.Seq[string], settings dialSettings) int {
+	dial := settings.start
 	zeroCounter := 0
 
-	for _, line := range data {
+	for line := range data {
 
 		direction := string(line[0])
 		number, err := strconv.Atoi(line[1:])
@@ -91,8 +109,8 @@ func calculateNewPassword(data []string) {
 		}
 
 		previousDial := dial
-		zeroCounter += number / 100
-		number = number % 100
+		zeroCounter += number / settings.size()
+		number = number % settings.size()
 
 		if direction == "R" {
 			dial += number
@@ -102,13 +120,13 @@ func calculateNewPassword(data []string) {
 
 		}
 
-		if dial > MaxDial {
-			dial = dial - MaxDial - 1
+		if dial > settings.max {
+			dial -= settings.size()
 			if previousDial != 0 {
 				zeroCounter += 1
 			}
 		} else if dial < minDial {
-			dial = MaxDial + dial + 1
+			dial += settings.size()
 			if previousDial != 0 {
 				zeroCounter += 1
 			}
@@ -118,28 +136,105 @@ func calculateNewPassword(data []string) {
 
 	}
 
-	fmt.Println("New password: ", zeroCounter)
+	fmt.Fprintln(w, "New password: ", zeroCounter)
 
+	return zeroCounter
 }
 
-func main() {
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
+
+	return calculateOldPassword(run.Out, readFile(run.Input), newDialSettings(run.Options))
+}
 
-	input1 := readFile("input1.txt")
-	input2 := readFile("input2.txt")
-	input3 := readFile("input3.txt")
+func solveSecond(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	fmt.Println("Input 1:")
-	calculateOldPassword(input1)
-	calculateNewPassword(input1)
-	fmt.Println()
+	return calculateNewPassword(run.Out, readFile(run.Input), newDialSettings(run.Options))
+}
+
+// anonymize nudges every rotation by a few clicks, keeping its direction and its
+// number of full turns of the dial set by the options, so the dial stops on different
+// positions.
+func anonymize(lines []string, rng *rand.Rand, options *runner.Options) []string {
+	var result []string
 
-	fmt.Println("Input 2:")
-	calculateOldPassword(input2)
-	calculateNewPassword(input2)
-	fmt.Println()
+	size := newDialSettings(options).size()
+	for _, line := range lines {
+		if len(line) < 2 {
+			log.Fatalf("invalid rotation %q", line)
+		}
+		number, err := strconv.Atoi(line[1:])
+		if err != nil {
+			log.Fatalf("error converting string to int: %s", err)
+		}
 
-	fmt.Println("Input 3:")
-	calculateOldPassword(input3)
-	calculateNewPassword(input3)
+		turns, clicks := number/size, number%size
+		clicks = ((clicks+rng.IntN(11)-5)%size + size) % size
+		if turns == 0 && clicks == 0 {
+			clicks = 1
+		}
 
+		result = append(result, fmt.Sprintf("%c%d", line[0], turns*size+clicks))
+	}
+
+	return result
+}
+
+// lint checks that every line is a rotation: L or R followed by a number of clicks.
+func lint(lines []string, _ *runner.Options) []runner.Issue {
+	var issues []runner.Issue
+
+	for i, line := range lines {
+		if line == "" {
+			issues = append(issues, runner.Issuef(i+1, "empty line"))
+			continue
+		}
+		if line[0] != 'L' && line[0] != 'R' {
+			issues = append(issues, runner.Issuef(i+1, "rotation %q does not start with L or R", line))
+			continue
+		}
+		if len(line) < 2 {
+			issues = append(issues, runner.Issuef(i+1, "rotation %q has no number of clicks", line))
+			continue
+		}
+		if number, err := strconv.Atoi(line[1:]); err != nil || number < 0 || line[1] == '+' {
+			issues = append(issues, runner.Issuef(i+1, "rotation %q does not end with a number of clicks", line))
+		}
+	}
+	if len(lines) == 0 {
+		issues = append(issues, runner.Issuef(0, "no rotations"))
+	}
+
+	return issues
+}
+
+// generate writes random rotations, each turning the dial less than ten times.
+func generate(w io.Writer, size int64, rng *rand.Rand) error {
+	for written := int64(0); written < size; {
+		direction := "LR"[rng.IntN(2)]
+		n, err := fmt.Fprintf(w, "%c%d\n", direction, 1+rng.IntN(999))
+		if err != nil {
+			return err
+		}
+		written += int64(n)
+	}
+	return nil
+}
+
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt", "input3.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt", "input3.txt"),
+		},
+		Anonymize: anonymize,
+		Generate:  generate,
+		Lint:      lint,
+	}
+}
+
+func main() {
+	runner.Main(newDay())
 }
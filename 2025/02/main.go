@@ -2,16 +2,21 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+
+	"aoc/checked"
+	"aoc/input"
+	"aoc/runner"
 )
 
 func readFile(filename string) string {
 
-	b, err := os.ReadFile(filename) // just pass the file name
+	b, err := input.ReadFile(filename) // just pass the file name
 	if err != nil {
-		fmt.Print(err)
+		fmt.Fprintln(os.Stderr, err)
 	}
 
 	str := string(b) // convert content to a 'string'
@@ -67,22 +72,22 @@ func checkIfValid(id string) bool {
 
 }
 
-func countInvalidIds(data [][]string) int {
+func countInvalidIds(w io.Writer, data [][]string) checked.Int {
 	invalidCount := 0
-	sum := 0
+	sum := checked.NewInt(0)
 
 	for _, pair := range data {
 		start, err := strconv.Atoi(pair[0])
 
 		if err != nil {
-			fmt.Println("Error converting start:", err)
+			fmt.Fprintln(w, "Error converting start:", err)
 			continue
 		}
 
 		end, err := strconv.Atoi(pair[1])
 
 		if err != nil {
-			fmt.Println("Error converting end:", err)
+			fmt.Fprintln(w, "Error converting end:", err)
 			continue
 		}
 
@@ -90,13 +95,13 @@ func countInvalidIds(data [][]string) int {
 			id := strconv.Itoa(i)
 			if !checkIfValid(id) {
 				invalidCount++
-				sum += i
+				sum = sum.AddInt(i)
 			}
 		}
 
 	}
 
-	fmt.Println("Total Invalid IDs: ", invalidCount)
+	fmt.Fprintln(w, "Total Invalid IDs: ", invalidCount)
 
 	return sum
 
@@ -132,22 +137,22 @@ func checkIfValidV2(id string) bool {
 
 }
 
-func countInvalidIdsV2(data [][]string) int {
+func countInvalidIdsV2(w io.Writer, data [][]string) checked.Int {
 	invalidCount := 0
-	sum := 0
+	sum := checked.NewInt(0)
 
 	for _, pair := range data {
 		start, err := strconv.Atoi(pair[0])
 
 		if err != nil {
-			fmt.Println("Error converting start:", err)
+			fmt.Fprintln(w, "Error converting start:", err)
 			continue
 		}
 
 		end, err := strconv.Atoi(pair[1])
 
 		if err != nil {
-			fmt.Println("Error converting end:", err)
+			fmt.Fprintln(w, "Error converting end:", err)
 			continue
 		}
 
@@ -155,60 +160,68 @@ func countInvalidIdsV2(data [][]string) int {
 			id := strconv.Itoa(i)
 			if !checkIfValidV2(id) {
 				invalidCount++
-				sum += i
+				sum = sum.AddInt(i)
 			}
 		}
 
 	}
 
-	fmt.Println("Total Invalid IDs: ", invalidCount)
+	fmt.Fprintln(w, "Total Invalid IDs: ", invalidCount)
 
 	return sum
 
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	input := readFile(filename)
-	//fmt.Println("Input: ", input)
+	input := readFile(run.Input)
+	//fmt.Fprintln(run.Out, "Input: ", input)
 
 	split := splitData(input)
-	//fmt.Println("Split Data: ", split)
+	//fmt.Fprintln(run.Out, "Split Data: ", split)
 
 	filtered := filterData(split)
-	//fmt.Println("Filtered Data: ", filtered)
+	//fmt.Fprintln(run.Out, "Filtered Data: ", filtered)
+
+	sum := countInvalidIds(run.Out, filtered)
+	fmt.Fprintln(run.Out, "Sum of Invalid IDs: ", sum)
 
-	sum := countInvalidIds(filtered)
-	fmt.Println("Sum of Invalid IDs: ", sum)
+	fmt.Fprintln(run.Out)
 
-	fmt.Println()
+	return sum
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	input := readFile(filename)
-	//fmt.Println("Input: ", input)
+	input := readFile(run.Input)
+	//fmt.Fprintln(run.Out, "Input: ", input)
 
 	split := splitData(input)
-	//fmt.Println("Split Data: ", split)
+	//fmt.Fprintln(run.Out, "Split Data: ", split)
 
 	filtered := filterData(split)
-	//fmt.Println("Filtered Data: ", filtered)
+	//fmt.Fprintln(run.Out, "Filtered Data: ", filtered)
 
-	sum := countInvalidIdsV2(filtered)
-	fmt.Println("Sum of Invalid IDs: ", sum)
+	sum := countInvalidIdsV2(run.Out, filtered)
+	fmt.Fprintln(run.Out, "Sum of Invalid IDs: ", sum)
 
-	fmt.Println()
-}
-
-func main() {
+	fmt.Fprintln(run.Out)
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+	return sum
+}
 
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+	}
+}
 
+func main() {
+	runner.Main(newDay())
 }
@@ -1,37 +1,33 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"log"
-	"os"
+	"math"
+	"math/rand/v2"
 	"strconv"
-)
-
-func readFile(filename string) []string {
-	var result []string
-
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
-	}
-	defer file.Close()
-
-	// Create a new scanner to read the file line by line
-	scanner := bufio.NewScanner(file)
 
-	// Loop through the file and read each line
-	for scanner.Scan() {
-		line := scanner.Text() // Get the line as a string
-		result = append(result, line)
-	}
+	"aoc/checked"
+	"aoc/input"
+	"aoc/runner"
+	"aoc/search"
+)
 
-	// Check for errors during the scan
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("error reading file: %s", err)
+// readFile streams the battery banks of the input one line at a time.
+func readFile(filename string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		lines, linesErr := input.Lines(filename)
+		for line := range lines {
+			if !yield(line) {
+				return
+			}
+		}
+		if err := linesErr(); err != nil {
+			log.Fatalf("error reading file: %s", err)
+		}
 	}
-
-	return result
 }
 
 func findMax2Batteries(bank string) int {
@@ -58,113 +54,136 @@ func findMax2Batteries(bank string) int {
 	return maxSum
 }
 
-// Global best found so far
-var globalBest int
+// defaultBatteryCount is the number of batteries turned on in each bank for the second
+// task, unless the batteries option says otherwise.
+const defaultBatteryCount = 12
 
-func findMax12Batteries(bank string, usedBatteries string) int {
-	if bank == "" && usedBatteries == "" {
-		return 0
-	}
-
-	if bank == "" || len(usedBatteries) == 12 {
-		currentSum, err := strconv.Atoi(usedBatteries)
-		if err != nil {
-			log.Fatalf("error converting string to int: %s", err)
-		}
-		if currentSum > globalBest {
-			globalBest = currentSum
-		}
-		return currentSum
-	}
-
-	// Pruning: calculate the maximum possible value we could
-	remainingSlots := 12 - len(usedBatteries)
-	if remainingSlots > len(bank) {
-		remainingSlots = len(bank)
-	}
-
-	if remainingSlots > 0 {
-		// Build the best possible number: current digits + all 9s
-		bestPossible := usedBatteries
-		for i := 0; i < remainingSlots; i++ {
-			bestPossible += "9"
-		}
-		maxPossible, err := strconv.Atoi(bestPossible)
+// selection is a partial choice of batteries: the first pos batteries of the bank
+// have been considered and count of them are turned on, forming value.
+type selection struct {
+	pos   int
+	count int
+	value int
+}
 
-		if err != nil {
-			log.Fatalf("error converting string to int: %s", err)
-		}
+func findMaxBatteries(bank string, batteryCount int) int {
+	result := search.BranchAndBound(search.Problem[selection, struct{}]{
+		Start: selection{},
+		Successors: func(s selection) iter.Seq[selection] {
+			return func(yield func(selection) bool) {
+				battery := bank[s.pos]
+				value, ok := checked.Mul(s.value, 10)
+				if ok {
+					value, ok = checked.Add(value, int(battery-'0'))
+				}
+				if !ok {
+					log.Fatalf("%d batteries make a joltage too large for an int", batteryCount)
+				}
+				withBattery := selection{pos: s.pos + 1, count: s.count + 1, value: value}
+				withoutBattery := selection{pos: s.pos + 1, count: s.count, value: s.value}
+
+				// Try taking the battery first if it's a high digit
+				first, second := withoutBattery, withBattery
+				if battery >= '5' {
+					first, second = withBattery, withoutBattery
+				}
+				if yield(first) {
+					yield(second)
+				}
+			}
+		},
+		Goal: func(s selection) bool {
+			return s.pos == len(bank) || s.count == batteryCount
+		},
+		Value: func(s selection) int {
+			return s.value
+		},
+		// The best possible number: current digits followed by all 9s, or the largest
+		// int when that does not fit, which still bounds every value that does
+		Bound: func(s selection) int {
+			remainingSlots := min(batteryCount-s.count, len(bank)-s.pos)
+			bestPossible := s.value
+			for i := 0; i < remainingSlots; i++ {
+				next, ok := checked.Mul(bestPossible, 10)
+				if ok {
+					next, ok = checked.Add(next, 9)
+				}
+				if !ok {
+					return math.MaxInt
+				}
+				bestPossible = next
+			}
+			return bestPossible
+		},
+	})
 
-		if maxPossible <= globalBest {
-			return 0 // This branch can't beat the best, prune it
-		}
-	}
+	return result.Value
+}
 
-	// Take the first battery
-	firstBattery := bank[0]
-	remainingBank := bank[1:]
-	newUsedBatteries := usedBatteries + string(firstBattery)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	// Sum if we use the first battery
-	sumWithBattery := 0
+	banks := readFile(run.Input)
 
-	// Sum if we don't use the first battery
-	sumWithoutBattery := 0
+	joltageSum := 0
+	for bank := range banks {
+		joltageSum += findMax2Batteries(bank)
 
-	// Try taking the battery first if it's a high digit
-	if firstBattery >= '5' {
-		sumWithBattery = findMax12Batteries(remainingBank, newUsedBatteries)
-		sumWithoutBattery = findMax12Batteries(remainingBank, usedBatteries)
-	} else {
-		sumWithoutBattery = findMax12Batteries(remainingBank, usedBatteries)
-		sumWithBattery = findMax12Batteries(remainingBank, newUsedBatteries)
 	}
 
-	result := sumWithBattery
-	if sumWithoutBattery > sumWithBattery {
-		result = sumWithoutBattery
-	}
+	fmt.Fprintf(run.Out, "Max joltage sum is: %d", joltageSum)
 
-	return result
-}
+	fmt.Fprintln(run.Out)
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+	return joltageSum
+}
 
-	banks := readFile(filename)
+func solveSecond(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	joltageSum := 0
-	for _, bank := range banks {
-		joltageSum += findMax2Batteries(bank)
+	banks := readFile(run.Input)
+	batteryCount := run.Options.Int("batteries", defaultBatteryCount)
+	if batteryCount < 1 {
+		log.Fatalf("invalid number of batteries: %d", batteryCount)
+	}
 
+	joltageSum := checked.NewInt(0)
+	for bank := range banks {
+		joltageSum = joltageSum.AddInt(findMaxBatteries(bank, batteryCount))
 	}
 
-	fmt.Printf("Max joltage sum is: %d", joltageSum)
+	fmt.Fprintf(run.Out, "Max joltage sum is: %v", joltageSum)
+	fmt.Fprintln(run.Out)
 
-	fmt.Println()
+	return joltageSum
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
-
-	banks := readFile(filename)
-
-	joltageSum := 0
-	for _, bank := range banks {
-		globalBest = 0 // Reset for each bank
-		joltageSum += findMax12Batteries(bank, "")
+// generate writes random banks of a hundred batteries, as in the puzzle's input.
+func generate(w io.Writer, size int64, rng *rand.Rand) error {
+	bank := make([]byte, 101)
+	bank[100] = '\n'
+	for written := int64(0); written < size; written += int64(len(bank)) {
+		for i := range 100 {
+			bank[i] = byte('1' + rng.IntN(9))
+		}
+		if _, err := w.Write(bank); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	fmt.Printf("Max joltage sum is: %d", joltageSum)
-	fmt.Println()
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+		Generate: generate,
+	}
 }
 
 func main() {
-
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
-	fmt.Println()
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
-
+	runner.Main(newDay())
 }
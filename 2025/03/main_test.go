@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"aoc/runner/runnertest"
+)
+
+func TestAnswers(t *testing.T) {
+	// Part 2 takes over a minute on the real input, and far longer with -race
+	runnertest.Answers(t, newDay(), runnertest.Solve{Part: 2, Input: "input2"})
+}
+
+func TestDeterministic(t *testing.T) {
+	runnertest.Deterministic(t, newDay(), runnertest.Solve{Part: 2, Input: "input2"})
+}
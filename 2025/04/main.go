@@ -4,14 +4,17 @@ import (
 	"bufio"
 	"fmt"
 	"log"
-	"os"
-	"strings"
+
+	"aoc/anim"
+	"aoc/grid"
+	"aoc/input"
+	"aoc/runner"
 )
 
-func readFile(filename string) [][]string {
-	var result [][]string
+func readFile(filename string) *grid.Grid[byte] {
+	var lines []string
 
-	file, err := os.Open(filename)
+	file, err := input.Open(filename)
 	if err != nil {
 		log.Fatalf("failed to open file: %s", err)
 	}
@@ -23,7 +26,7 @@ func readFile(filename string) [][]string {
 	// Loop through the file and read each line
 	for scanner.Scan() {
 		line := scanner.Text() // Get the line as a string
-		result = append(result, strings.Split(line, ""))
+		lines = append(lines, line)
 	}
 
 	// Check for errors during the scan
@@ -31,111 +34,89 @@ func readFile(filename string) [][]string {
 		log.Fatalf("error reading file: %s", err)
 	}
 
-	return result
-}
-
-func isPositionAccessible(board [][]string, x int, y int) bool {
-
-	boardHeight := len(board)
-	boardWidth := len(board[0])
+	board, err := grid.Parse(lines)
+	if err != nil {
+		log.Fatalf("invalid board: %s", err)
+	}
 
-	adjacentRolls := 0
+	return board
+}
 
-	// left
-	if x > 0 && board[y][x-1] == "@" {
-		adjacentRolls++
-	}
-	// right
-	if x < boardWidth-1 && board[y][x+1] == "@" {
-		adjacentRolls++
-	}
-	// up
-	if y > 0 && board[y-1][x] == "@" {
-		adjacentRolls++
-	}
-	// down
-	if y < boardHeight-1 && board[y+1][x] == "@" {
-		adjacentRolls++
-	}
-	// diagonal up-left
-	if x > 0 && y > 0 && board[y-1][x-1] == "@" {
-		adjacentRolls++
-	}
-	// diagonal up-right
-	if x < boardWidth-1 && y > 0 && board[y-1][x+1] == "@" {
-		adjacentRolls++
-	}
-	// diagonal down-left
-	if x > 0 && y < boardHeight-1 && board[y+1][x-1] == "@" {
-		adjacentRolls++
-	}
-	// diagonal down-right
-	if x < boardWidth-1 && y < boardHeight-1 && board[y+1][x+1] == "@" {
-		adjacentRolls++
-	}
+func isRoll(cell byte) bool {
+	return cell == '@'
+}
 
-	return adjacentRolls < 4
+func isPositionAccessible(board *grid.Grid[byte], position grid.Point) bool {
+	return board.CountNeighbours8(position, isRoll) < 4
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	board := readFile(filename)
-	boardHeight := len(board)
-	boardWidth := len(board[0])
+	board := readFile(run.Input)
 	accessibleRolls := 0
 
-	for i := 0; i < boardHeight; i++ {
-		for j := 0; j < boardWidth; j++ {
-			if board[i][j] == "@" {
-				if isPositionAccessible(board, j, i) {
-					accessibleRolls++
-				}
-			}
+	for _, position := range board.FindAll(isRoll) {
+		if isPositionAccessible(board, position) {
+			accessibleRolls++
 		}
 	}
 
-	fmt.Println("Number of accessible rolls: ", accessibleRolls)
+	fmt.Fprintln(run.Out, "Number of accessible rolls: ", accessibleRolls)
 
-	fmt.Println()
+	fmt.Fprintln(run.Out)
+
+	return accessibleRolls
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	board := readFile(filename)
-	boardHeight := len(board)
-	boardWidth := len(board[0])
+	board := readFile(run.Input)
 	removedRolls := 0
 	hasRemovedRolls := true
 
-	for loop := true; loop; loop = hasRemovedRolls {
-		hasRemovedRolls = false
-		for i := 0; i < boardHeight; i++ {
-			for j := 0; j < boardWidth; j++ {
-				if board[i][j] == "@" {
-					if isPositionAccessible(board, j, i) {
-						board[i][j] = "x"
-						removedRolls++
-						hasRemovedRolls = true
-					}
-				}
+	if run.Frames.Enabled() {
+		run.Frames.Add(anim.Snapshot(board, "Initial board"))
+	}
 
+	for wave := 1; hasRemovedRolls; wave++ {
+		hasRemovedRolls = false
+		var removedInWave []grid.Point
+		for _, position := range board.FindAll(isRoll) {
+			if isPositionAccessible(board, position) {
+				board.Set(position, 'x')
+				removedRolls++
+				hasRemovedRolls = true
+				removedInWave = append(removedInWave, position)
 			}
 		}
+
+		// Show each wave of removals, with the rolls removed in it highlighted
+		if hasRemovedRolls && run.Frames.Enabled() {
+			frame := anim.Snapshot(board, fmt.Sprintf("Wave %d: removed %d rolls, %d in total", wave, len(removedInWave), removedRolls))
+			frame.Highlight(anim.Red, removedInWave...)
+			run.Frames.Add(frame)
+		}
 	}
 
-	fmt.Println("Number of removed rolls: ", removedRolls)
+	fmt.Fprintln(run.Out, "Number of removed rolls: ", removedRolls)
 
-	fmt.Println()
-}
+	fmt.Fprintln(run.Out)
 
-func main() {
+	return removedRolls
+}
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
-	fmt.Println()
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+	}
+}
 
+func main() {
+	runner.Main(newDay())
 }
@@ -1,85 +1,108 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"log"
-	"os"
+	"math/rand/v2"
+	"slices"
 	"strconv"
 	"strings"
-)
 
-func readFile(filename string) ([][]int, []int) {
-	var ranges [][]int
-	var ingredients []int
-	var passedBlankLine bool = false
+	"aoc/checked"
+	"aoc/input"
+	"aoc/interval"
+	"aoc/parse"
+	"aoc/runner"
+	"aoc/trace"
+)
 
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
-	}
-	defer file.Close()
+// freshRange is a range of fresh ingredient IDs, written as lo-hi.
+type freshRange struct {
+	Lo int64 `suffix:"-"`
+	Hi int64
+}
 
-	// Create a new scanner to read the file line by line
-	scanner := bufio.NewScanner(file)
+// readRanges streams the input up to the blank line, returning only the fresh ranges.
+func readRanges(filename string) []interval.Interval[int64] {
+	var ranges []interval.Interval[int64]
 
-	// Loop through the file and read each line
-	for scanner.Scan() {
-		line := scanner.Text() // Get the line as a string
+	lines, linesErr := input.Lines(filename)
+	number := 0
+	for line := range lines {
+		number++
 		if line == "" {
-			passedBlankLine = true
-			continue
+			break
 		}
-
-		if passedBlankLine {
-			newIngredient, err := strconv.Atoi(line)
-			if err != nil {
-				log.Fatalf("error converting string to int: %s", err)
-			}
-
-			ingredients = append(ingredients, newIngredient)
-		} else {
-			tempRange := strings.Split(line, "-")
-			minRange, err := strconv.Atoi(tempRange[0])
-			if err != nil {
-				log.Fatalf("error converting string to int: %s", err)
-			}
-
-			maxRange, err := strconv.Atoi(tempRange[1])
-			if err != nil {
-				log.Fatalf("error converting string to int: %s", err)
-			}
-
-			ranges = append(ranges, []int{minRange, maxRange})
+		r, err := parse.Line[freshRange](line)
+		if err != nil {
+			log.Fatalf("%s:%d: %s", filename, number, err)
 		}
-
+		ranges = append(ranges, interval.Interval[int64]{Lo: r.Lo, Hi: r.Hi})
 	}
-
-	// Check for errors during the scan
-	if err := scanner.Err(); err != nil {
+	if err := linesErr(); err != nil {
 		log.Fatalf("error reading file: %s", err)
 	}
 
-	return ranges, ingredients
+	return ranges
 }
 
-func isFresh(ingredient int, ranges [][]int) bool {
-	for _, r := range ranges {
-		minRange, maxRange := r[0], r[1]
+// readInventory reads the fresh ranges of the input and returns them with the
+// ingredient IDs after the blank line. The IDs are streamed from the same pass over
+// the input as the ranges, so the sequence may only be iterated once.
+func readInventory(filename string) ([]interval.Interval[int64], iter.Seq[int64]) {
+	lines, linesErr := input.Lines(filename)
+	next, stop := iter.Pull(lines)
+
+	var ranges []interval.Interval[int64]
+	number := 0
+	for {
+		line, ok := next()
+		if !ok || line == "" {
+			break
+		}
+		number++
+		r, err := parse.Line[freshRange](line)
+		if err != nil {
+			log.Fatalf("%s:%d: %s", filename, number, err)
+		}
+		ranges = append(ranges, interval.Interval[int64]{Lo: r.Lo, Hi: r.Hi})
+	}
+	number++
 
-		if ingredient >= minRange && ingredient <= maxRange {
-			return true
+	ingredients := func(yield func(int64) bool) {
+		defer stop()
+		for {
+			line, ok := next()
+			if !ok {
+				break
+			}
+			number++
+			ingredient, err := parse.Line[int64](line)
+			if err != nil {
+				log.Fatalf("%s:%d: %s", filename, number, err)
+			}
+			if !yield(ingredient) {
+				return
+			}
+		}
+		if err := linesErr(); err != nil {
+			log.Fatalf("error reading file: %s", err)
 		}
 	}
+	return ranges, ingredients
+}
 
-	return false
+func isFresh(ingredient int64, freshRanges *interval.Set[int64]) bool {
+	return freshRanges.Contains(ingredient)
 }
 
-func countFreshIngredients(ingredients []int, ranges [][]int) int {
+func countFreshIngredients(ingredients iter.Seq[int64], freshRanges *interval.Set[int64]) int {
 	count := 0
 
-	for _, ingredient := range ingredients {
-		if isFresh(ingredient, ranges) {
+	for ingredient := range ingredients {
+		if isFresh(ingredient, freshRanges) {
 			count++
 		}
 	}
@@ -87,85 +110,261 @@ func countFreshIngredients(ingredients []int, ranges [][]int) int {
 	return count
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
+
+	// The ranges are few, but there may be too many ingredients to hold in memory
+	ranges, ingredients := readInventory(run.Input)
+	freshRanges := combineOverlappingRanges(ranges)
 
-	ranges, ingredients := readFile(filename)
+	var explanation *freshExplanation
+	if run.Trace.Enabled() {
+		explanation = newFreshExplanation(run.Trace.Limit())
+		ingredients = explanation.observe(ingredients, freshRanges)
+	}
+	freshCount := countFreshIngredients(ingredients, freshRanges)
 
-	freshCount := countFreshIngredients(ingredients, ranges)
+	fmt.Fprintln(run.Out, "Number of fresh ingredients: ", freshCount)
 
-	fmt.Println("Number of fresh ingredients: ", freshCount)
+	if explanation != nil {
+		explanation.write(run.Trace, len(ranges), freshRanges)
+	}
 
-	fmt.Println()
-}
+	fmt.Fprintln(run.Out)
 
-func combineOverlappingRanges(ranges [][]int) [][]int {
+	return freshCount
+}
 
-	for i := 0; i < len(ranges); i++ {
-		minRange1, maxRange1 := ranges[i][0], ranges[i][1]
+// freshExplanation collects which merged range holds each fresh ingredient, to explain
+// part 1. Only the IDs of as many ingredients as the trace keeps steps are collected,
+// so that a large inventory is not held in memory to be explained; the others are only
+// counted.
+type freshExplanation struct {
+	limit        int
+	collected    int
+	held         map[interval.Interval[int64]][]int64
+	heldCount    map[interval.Interval[int64]]int
+	spoiled      []int64
+	spoiledCount int
+}
 
-		for j := i + 1; j < len(ranges); j++ {
-			minRange2, maxRange2 := ranges[j][0], ranges[j][1]
+func newFreshExplanation(limit int) *freshExplanation {
+	return &freshExplanation{
+		limit:     limit,
+		held:      map[interval.Interval[int64]][]int64{},
+		heldCount: map[interval.Interval[int64]]int{},
+	}
+}
 
-			// Check for overlaps
-			if maxRange1 >= minRange2 && minRange1 <= maxRange2 {
-				newMin := minRange1
-				if minRange2 < newMin {
-					newMin = minRange2
+// observe returns the ingredients, collecting each one as it is iterated.
+func (e *freshExplanation) observe(ingredients iter.Seq[int64], freshRanges *interval.Set[int64]) iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		for ingredient := range ingredients {
+			collect := e.limit == 0 || e.collected < e.limit
+			if freshRange, ok := freshRanges.Find(ingredient); ok {
+				e.heldCount[freshRange]++
+				if collect {
+					e.held[freshRange] = append(e.held[freshRange], ingredient)
+					e.collected++
 				}
-
-				newMax := maxRange1
-				if maxRange2 > newMax {
-					newMax = maxRange2
+			} else {
+				e.spoiledCount++
+				if collect {
+					e.spoiled = append(e.spoiled, ingredient)
+					e.collected++
 				}
-
-				ranges[i] = []int{newMin, newMax}
-
-				// Remove the j-th range as it has been merged
-				ranges = append(ranges[:j], ranges[j+1:]...)
-				i = 0 // Restart from the beginning
-				break
+			}
+			if !yield(ingredient) {
+				return
 			}
 		}
 	}
-
-	return ranges
-
 }
 
-func countItemInRanges(ranges [][]int) int {
-	count := 0
+// write records the ingredients collected, by the merged range holding them.
+func (e *freshExplanation) write(t *trace.Trace, ranges int, freshRanges *interval.Set[int64]) {
+	merged := t.Section("%d ranges merge into %d disjoint ranges", ranges, freshRanges.Count())
+	for freshRange := range freshRanges.All() {
+		if count := e.heldCount[freshRange]; count > 0 {
+			merged.Note("%v holds %d fresh ingredients: %s", freshRange, count, listIDs(e.held[freshRange], count))
+		}
+	}
+	t.Note("%d ingredients are in no range and spoiled: %s", e.spoiledCount, listIDs(e.spoiled, e.spoiledCount))
+}
 
-	for _, r := range ranges {
-		minRange, maxRange := r[0], r[1]
-		count += (maxRange - minRange + 1)
+// listIDs formats the collected IDs of count ingredients, saying how many were left out.
+func listIDs(ids []int64, count int) string {
+	switch len(ids) {
+	case count:
+		return fmt.Sprint(ids)
+	case 0:
+		return "not listed, past the explanation limit"
 	}
+	return fmt.Sprintf("%v and %d more", ids, count-len(ids))
+}
 
-	return count
+// combineOverlappingRanges merges the ranges into a set of sorted, disjoint intervals.
+func combineOverlappingRanges(ranges []interval.Interval[int64]) *interval.Set[int64] {
+	return interval.New(ranges...)
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) int64 {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	ranges, _ := readFile(filename)
+	ranges := readRanges(run.Input)
 
 	combinedRanges := combineOverlappingRanges(ranges)
 
-	countFreshItems := countItemInRanges(combinedRanges)
+	countFreshItems := combinedRanges.Len()
+
+	fmt.Fprintln(run.Out, "Number of fresh ingredients: ", countFreshItems)
+
+	if run.Trace.Enabled() {
+		merged := run.Trace.Section("%d ranges merge into %d disjoint ranges", len(ranges), combinedRanges.Count())
+		for freshRange := range combinedRanges.All() {
+			merged.Note("%v holds %d IDs", freshRange, freshRange.Hi-freshRange.Lo+1)
+		}
+	}
 
-	fmt.Println("Number of fresh ingredients: ", countFreshItems)
+	fmt.Fprintln(run.Out)
 
-	fmt.Println()
+	return countFreshItems
 }
 
-func main() {
+// anonymize stretches and shifts every range and ingredient by the same amounts, which
+// keeps which ingredients are fresh but changes the size of the ranges, and then
+// drops about one ingredient in ten.
+func anonymize(lines []string, rng *rand.Rand, _ *runner.Options) []string {
+	var result []string
+	var passedBlankLine bool = false
+
+	scale := 2 + rng.IntN(4)
+	shift := rng.IntN(1_000_000_000)
+	transform := func(value string) string {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("error converting string to int: %s", err)
+		}
+		// IDs near the limit of an int64 cannot be stretched without overflowing
+		scaled, ok := checked.Mul(number, scale)
+		if ok {
+			scaled, ok = checked.Add(scaled, shift)
+		}
+		if !ok {
+			log.Fatalf("ID %d is too large to anonymize: scaled by %d and shifted by %d it overflows", number, scale, shift)
+		}
+		return strconv.Itoa(scaled)
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			passedBlankLine = true
+			result = append(result, line)
+			continue
+		}
+
+		if passedBlankLine {
+			if rng.IntN(10) == 0 {
+				continue
+			}
+			result = append(result, transform(line))
+		} else {
+			tempRange := strings.Split(line, "-")
+			result = append(result, transform(tempRange[0])+"-"+transform(tempRange[1]))
+		}
+	}
+
+	return result
+}
+
+// generate writes two hundred random ranges, which are all a real input has, and then
+// random ingredient IDs until the input is large enough.
+func generate(w io.Writer, size int64, rng *rand.Rand) error {
+	const maxID = 1_000_000_000_000_000
+
+	var written int64
+	for range 200 {
+		lo := rng.Int64N(maxID)
+		n, err := fmt.Fprintf(w, "%d-%d\n", lo, lo+rng.Int64N(maxID/1000))
+		if err != nil {
+			return err
+		}
+		written += int64(n)
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+	for written < size {
+		n, err := fmt.Fprintln(w, rng.Int64N(maxID))
+		if err != nil {
+			return err
+		}
+		written += int64(n)
+	}
+	return nil
+}
 
-	fmt.Println()
+// commands lets the fresh ranges of an input be queried interactively.
+func commands(run *runner.Run) map[string]runner.Command {
+	ranges, stream := readInventory(run.Input)
+	ingredients := slices.Collect(stream)
+	freshRanges := combineOverlappingRanges(ranges)
+
+	return map[string]runner.Command{
+		"fresh": {
+			Args: "<id>...",
+			Help: "tell whether ingredients are fresh and which range holds them",
+			Run: func(w io.Writer, args []string) error {
+				for _, arg := range args {
+					ingredient, err := strconv.ParseInt(arg, 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid ingredient ID %q", arg)
+					}
+					if !isFresh(ingredient, freshRanges) {
+						fmt.Fprintf(w, "%d: spoiled\n", ingredient)
+						continue
+					}
+					freshRange, _ := freshRanges.Find(ingredient)
+					fmt.Fprintf(w, "%d: fresh, in %v\n", ingredient, freshRange)
+				}
+				return nil
+			},
+		},
+		"ranges": {
+			Help: "list the fresh ranges after merging overlapping ones",
+			Run: func(w io.Writer, args []string) error {
+				for freshRange := range freshRanges.All() {
+					fmt.Fprintln(w, freshRange)
+				}
+				fmt.Fprintf(w, "%d ranges merged into %d\n", len(ranges), freshRanges.Count())
+				return nil
+			},
+		},
+		"count": {
+			Help: "count the fresh IDs and the fresh ingredients of the input",
+			Run: func(w io.Writer, args []string) error {
+				fmt.Fprintf(w, "%d fresh IDs, %d of %d ingredients fresh\n",
+					freshRanges.Len(), countFreshIngredients(slices.Values(ingredients), freshRanges), len(ingredients))
+				return nil
+			},
+		},
+	}
+}
 
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+		Anonymize: anonymize,
+		REPL:      commands,
+		Generate:  generate,
+	}
+}
 
+func main() {
+	runner.Main(newDay())
 }
@@ -1,218 +1,202 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"log"
-	"os"
-	"strconv"
-	"strings"
-)
-
-func readFile(filename string) [][]string {
-	var result [][]string
-
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
+	"math/rand/v2"
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		result = append(result, strings.Fields(line))
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("error reading file: %s", err)
-	}
-
-	return result
-}
-
-func readFileWithSpaces(filename string) []string {
-	var result []string
-
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		result = append(result, line)
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("error reading file: %s", err)
-	}
-
-	return result
-}
-
-func findLongestInstruction(instructions []string) int {
-	longest := 0
-
-	for i := 0; i < len(instructions); i++ {
-		if len(instructions[i]) > longest {
-			longest = len(instructions[i])
-		}
-	}
+	"aoc/checked"
+	"aoc/input"
+	"aoc/runner"
+)
 
-	return longest
+// problem is one problem of the worksheet. The first task reads its numbers across
+// the rows, the second reads them down the columns.
+type problem struct {
+	operator byte
+	rows     []checked.Int
+	columns  []checked.Int
 }
 
-func fixInstructions(instructions []string) [][]string {
-
-	var longestInstruction int = findLongestInstruction(instructions)
-
-	for i := 0; i < longestInstruction; i++ {
-		hasValueSomewhere := false
+// readProblems streams the problems of the worksheet column by column, so that only
+// the problem being read is held in memory however long the lines are.
+func readProblems(filename string) iter.Seq[problem] {
+	return func(yield func(problem) bool) {
+		columns, columnsErr := input.Columns(filename)
+		var current *problem
 
-		// Find if there is any value in this column
-		for j := 0; j < len(instructions); j++ {
-			item := string(instructions[j][i])
+		for column := range columns {
+			digits, operator := column[:len(column)-1], column[len(column)-1]
 
-			if item == "+" || item == "*" || item == " " {
+			// A column of spaces separates two problems
+			if isBlank(column) {
+				if current != nil && !yield(*current) {
+					return
+				}
+				current = nil
 				continue
 			}
-			hasValueSomewhere = true
-			break
-		}
 
-		// Fill the column if necessary
-		if hasValueSomewhere {
-			for j := 0; j < len(instructions)-1; j++ {
-				item := string(instructions[j][i])
+			if current == nil {
+				current = &problem{rows: make([]checked.Int, len(digits))}
+				for row := range current.rows {
+					current.rows[row] = checked.NewInt(0)
+				}
+			}
+			if operator != ' ' {
+				current.operator = operator
+			}
 
-				if item == " " {
-					instructions[j] = instructions[j][:i] + "#" + instructions[j][i+1:]
+			number := checked.NewInt(0)
+			for row, digit := range digits {
+				if digit == ' ' {
+					continue
+				}
+				if digit < '0' || digit > '9' {
+					log.Fatalf("invalid digit %q in %s", digit, filename)
 				}
+				current.rows[row] = current.rows[row].MulInt(10).AddInt(int(digit - '0'))
+				number = number.MulInt(10).AddInt(int(digit - '0'))
 			}
+			current.columns = append(current.columns, number)
 		}
 
+		if err := columnsErr(); err != nil {
+			log.Fatalf("error reading file: %s", err)
+		}
+		if current != nil {
+			yield(*current)
+		}
 	}
+}
 
-	// Spit the instruction into 2D array
-	var fixedInstructions [][]string
-	for j := 0; j < len(instructions); j++ {
-		fixedInstructions = append(fixedInstructions, strings.Fields(instructions[j]))
+func isBlank(column []byte) bool {
+	for _, c := range column {
+		if c != ' ' {
+			return false
+		}
 	}
-
-	return fixedInstructions
-
+	return true
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
-
-	sum := 0
-
-	instructions := readFile(filename)
-
-	numbersLen := len(instructions) - 1
-	indexOfSymbols := numbersLen
-	problemSize := len(instructions[0])
-
-	for i := 0; i < problemSize; i++ {
-		currentSymbol := instructions[indexOfSymbols][i]
-		currentResult := 0
-
-		if currentSymbol == "*" {
-			currentResult = 1
+// evaluate adds or multiplies the numbers of a problem.
+func evaluate(operator byte, numbers []checked.Int) checked.Int {
+	var result checked.Int
+	switch operator {
+	case '+':
+		result = checked.NewInt(0)
+		for _, number := range numbers {
+			result = result.Add(number)
 		}
-
-		for j := 0; j < numbersLen; j++ {
-			number, err := strconv.Atoi(instructions[j][i])
-
-			if err != nil {
-				log.Fatalf("error converting string to int: %s", err)
-			}
-
-			if currentSymbol == "+" {
-				currentResult += number
-			} else if currentSymbol == "*" {
-				currentResult *= number
-			}
-
+	case '*':
+		result = checked.NewInt(1)
+		for _, number := range numbers {
+			result = result.Mul(number)
 		}
-
-		sum += currentResult
-
+	default:
+		log.Fatalf("invalid operator %q", operator)
 	}
-
-	fmt.Println("Sum is: ", sum)
-
-	fmt.Println()
+	return result
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveFirst(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	sum := 0
-	instructions := readFileWithSpaces(filename)
-	fixedInstructions := fixInstructions(instructions)
+	sum := checked.NewInt(0)
+	for p := range readProblems(run.Input) {
+		sum = sum.Add(evaluate(p.operator, p.rows))
+	}
 
-	instructionsSize := len(fixedInstructions) - 1
-	indexOfSymbols := instructionsSize
-	numbersSize := len(fixedInstructions[0])
+	fmt.Fprintln(run.Out, "Sum is: ", sum)
 
-	for i := numbersSize - 1; i >= 0; i-- {
-		currentSymbol := fixedInstructions[indexOfSymbols][i]
-		columnWidth := len(fixedInstructions[0][i])
-		columnSum := 0
+	fmt.Fprintln(run.Out)
 
-		if currentSymbol == "*" {
-			columnSum = 1
-		}
+	return sum
+}
 
-		for j := 0; j < columnWidth; j++ {
+func solveSecond(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-			concatString := ""
+	sum := checked.NewInt(0)
+	for p := range readProblems(run.Input) {
+		sum = sum.Add(evaluate(p.operator, p.columns))
+	}
 
-			for k := instructionsSize - 1; k >= 0; k-- {
-				item := string(fixedInstructions[k][i][j])
+	fmt.Fprintln(run.Out, "Sum is: ", sum)
 
-				if item == "#" {
-					continue
-				}
+	return sum
+}
 
-				concatString = item + concatString
+// generate writes a worksheet of four rows of numbers and a row of operators, with
+// problems of up to four digits whose numbers are aligned left or right at random.
+// The worksheet is written row by row, so every row replays the same problem widths
+// and operators from a generator seeded alike.
+func generate(w io.Writer, size int64, rng *rand.Rand) error {
+	const numberRows = 4
+	rowSize := size / (numberRows + 1)
+	layoutSeed := rng.Uint64()
+
+	for row := range numberRows + 1 {
+		layout := rand.New(rand.NewPCG(layoutSeed, layoutSeed))
+		line := make([]byte, 0, 5)
+
+		for written := int64(0); written < rowSize; {
+			width := 1 + layout.IntN(4)
+			operator := "+*"[layout.IntN(2)]
+
+			line = line[:0]
+			if written > 0 {
+				line = append(line, ' ')
+			}
+			start := len(line)
+			for range width {
+				line = append(line, ' ')
 			}
 
-			number, err := strconv.Atoi(concatString)
-			if err != nil {
-				log.Fatalf("error converting string to int: %s", err)
+			if row == numberRows {
+				line[start] = operator
+			} else {
+				// The first row fills the problem's width so that no column of it is blank
+				digits := width
+				if row > 0 {
+					digits = 1 + rng.IntN(width)
+				}
+				offset := 0
+				if rng.IntN(2) == 0 {
+					offset = width - digits
+				}
+				for i := range digits {
+					line[start+offset+i] = byte('1' + rng.IntN(9))
+				}
 			}
 
-			if currentSymbol == "+" {
-				columnSum += number
-			} else if currentSymbol == "*" {
-				columnSum *= number
+			n, err := w.Write(line)
+			if err != nil {
+				return err
 			}
+			written += int64(n)
 		}
 
-		sum += columnSum
-
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	fmt.Println("Sum is: ", sum)
-
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+		Generate: generate,
+	}
 }
 
 func main() {
-
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
-
-	fmt.Println()
-
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
-
+	runner.Main(newDay())
 }
@@ -4,14 +4,18 @@ import (
 	"bufio"
 	"fmt"
 	"log"
-	"os"
-	"strings"
+
+	"aoc/anim"
+	"aoc/checked"
+	"aoc/grid"
+	"aoc/input"
+	"aoc/runner"
 )
 
-func readFile(filename string) [][]string {
-	var result [][]string
+func readFile(filename string) *grid.Grid[byte] {
+	var lines []string
 
-	file, err := os.Open(filename)
+	file, err := input.Open(filename)
 	if err != nil {
 		log.Fatalf("failed to open file: %s", err)
 	}
@@ -21,38 +25,80 @@ func readFile(filename string) [][]string {
 
 	for scanner.Scan() {
 		line := scanner.Text()
-		result = append(result, strings.Split(line, ""))
+		lines = append(lines, line)
 	}
 
 	if err := scanner.Err(); err != nil {
 		log.Fatalf("error reading file: %s", err)
 	}
 
-	return result
+	manifold, err := grid.Parse(lines)
+	if err != nil {
+		log.Fatalf("invalid manifold: %s", err)
+	}
+
+	return manifold
+}
+
+// beamFrames draws the beams found so far onto a copy of the manifold to record
+// animation frames. A nil *beamFrames records nothing.
+type beamFrames struct {
+	recorder *anim.Recorder
+	picture  *grid.Grid[byte]
+}
+
+func newBeamFrames(recorder *anim.Recorder, manifold *grid.Grid[byte]) *beamFrames {
+	if !recorder.Enabled() {
+		return nil
+	}
+	return &beamFrames{recorder: recorder, picture: manifold.Clone()}
+}
+
+// record adds a frame showing the beams on the given line, highlighted, below the earlier ones.
+func (b *beamFrames) record(line int, beams map[int]int, splitCount int) {
+	if b == nil || !b.recorder.Enabled() {
+		return
+	}
+
+	var current []grid.Point
+	for j := 0; j < b.picture.Width(); j++ {
+		if beams[j] != 1 {
+			continue
+		}
+		position := grid.Point{X: j, Y: line}
+		if b.picture.At(position) == '.' {
+			b.picture.Set(position, '|')
+		}
+		current = append(current, position)
+	}
+
+	frame := anim.Snapshot(b.picture, fmt.Sprintf("Line %d: %d beams, %d splits", line+1, len(current), splitCount))
+	frame.Highlight(anim.Yellow, current...)
+	b.recorder.Add(frame)
 }
 
-func countBeams(grid [][]string, line int, nextLocations map[int]int) int {
+func countBeams(manifold *grid.Grid[byte], line int, nextLocations map[int]int, frames *beamFrames) int {
 
-	if line >= len(grid) {
+	if line >= manifold.Height() {
 		return 0
 	}
 
 	var newNextLocations = make(map[int]int)
 	splitCount := 0
-	currentLine := grid[line]
+	currentLine := manifold.Row(line)
 	lineLength := len(currentLine)
 
 	for j := 0; j < lineLength; j++ {
-		symbol := grid[line][j]
+		symbol := currentLine[j]
 		hasLine := nextLocations[j] == 1
 
-		if symbol == "S" {
+		if symbol == 'S' {
 			// Here laser beam starts
 			newNextLocations[j] = 1
 			break
 
 		} else if hasLine {
-			if symbol == "^" {
+			if symbol == '^' {
 				// Here laser beam splits
 				splitCount++
 
@@ -70,112 +116,122 @@ func countBeams(grid [][]string, line int, nextLocations map[int]int) int {
 		}
 	}
 
-	return countBeams(grid, line+1, newNextLocations) + splitCount
+	frames.record(line, newNextLocations, splitCount)
+
+	return countBeams(manifold, line+1, newNextLocations, frames) + splitCount
+}
+
+// timelineCounter counts timelines for one manifold, memoizing the count for each cell.
+type timelineCounter struct {
+	manifold        *grid.Grid[byte]
+	memoizationTree [][]checked.Int
 }
 
-var memoizationTree [][]int
+func newTimelineCounter(manifold *grid.Grid[byte]) *timelineCounter {
+	memoizationTree := make([][]checked.Int, manifold.Height())
+	for i := range memoizationTree {
+		memoizationTree[i] = make([]checked.Int, manifold.Width())
+		for j := range memoizationTree[i] {
+			memoizationTree[i][j] = checked.NewInt(-1)
+		}
+	}
+
+	return &timelineCounter{manifold: manifold, memoizationTree: memoizationTree}
+}
 
-func countTimelines(grid [][]string, line int, currentIndex int) int {
+func (c *timelineCounter) countTimelines(line int, currentIndex int) checked.Int {
+	manifold := c.manifold
 
-	if line >= len(grid) {
-		return 1
+	if line >= manifold.Height() {
+		return checked.NewInt(1)
 	}
 
-	TimelinesCount := 0
-	currentLine := grid[line]
+	TimelinesCount := checked.NewInt(0)
+	currentLine := manifold.Row(line)
 	lineLength := len(currentLine)
 
 	if currentIndex == -1 {
 		// Find the starting point
 
 		for j := 0; j < lineLength; j++ {
-			symbol := grid[line][j]
-			if symbol == "S" {
-				return countTimelines(grid, line+1, j)
+			symbol := currentLine[j]
+			if symbol == 'S' {
+				return c.countTimelines(line+1, j)
 			}
 		}
 	}
 
-	// Check memoization tree
-	if memoizationTree[line][currentIndex] != -1 {
-		return memoizationTree[line][currentIndex]
+	// Check memoization tree, where negative values mark cells not computed yet
+	if c.memoizationTree[line][currentIndex].Sign() >= 0 {
+		return c.memoizationTree[line][currentIndex]
 	}
 
-	symbol := grid[line][currentIndex]
+	symbol := currentLine[currentIndex]
 
-	if symbol == "^" {
+	if symbol == '^' {
 		// Here laser beam splits
 
 		if currentIndex > 0 {
 			// left branch
 
-			TimelinesCount += countTimelines(grid, line+1, currentIndex-1)
+			TimelinesCount = TimelinesCount.Add(c.countTimelines(line+1, currentIndex-1))
 		}
 		if currentIndex < lineLength-1 {
 			// right branch
 
-			TimelinesCount += countTimelines(grid, line+1, currentIndex+1)
+			TimelinesCount = TimelinesCount.Add(c.countTimelines(line+1, currentIndex+1))
 		}
 
 	} else {
 		// Here laser beam continues
 
-		TimelinesCount += countTimelines(grid, line+1, currentIndex)
+		TimelinesCount = TimelinesCount.Add(c.countTimelines(line+1, currentIndex))
 	}
 
 	// Save to memoization tree
-	memoizationTree[line][currentIndex] = TimelinesCount
+	c.memoizationTree[line][currentIndex] = TimelinesCount
 
 	return TimelinesCount
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	input := readFile(filename)
+	input := readFile(run.Input)
 
-	splitCount := countBeams(input, 0, make(map[int]int))
+	splitCount := countBeams(input, 0, make(map[int]int), newBeamFrames(run.Frames, input))
 
-	fmt.Println("Number of splits: ", splitCount)
+	fmt.Fprintln(run.Out, "Number of splits: ", splitCount)
 
-	fmt.Println()
-}
+	fmt.Fprintln(run.Out)
 
-func initMemoizationTree(length int, width int) {
-	memoizationTree = make([][]int, length)
-	for i := 0; i < length; i++ {
-		memoizationTree[i] = make([]int, width)
-		for j := 0; j < width; j++ {
-			memoizationTree[i][j] = -1
-		}
-	}
+	return splitCount
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	input := readFile(filename)
+	input := readFile(run.Input)
 
-	inputLength := len(input)
-	inputWidth := len(input[0])
+	timelinesCount := newTimelineCounter(input).countTimelines(0, -1)
 
-	initMemoizationTree(inputLength, inputWidth)
+	fmt.Fprintln(run.Out, "Number of timelines: ", timelinesCount)
 
-	timelinesCount := countTimelines(input, 0, -1)
+	fmt.Fprintln(run.Out)
 
-	fmt.Println("Number of timelines: ", timelinesCount)
+	return timelinesCount
+}
 
-	fmt.Println()
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+	}
 }
 
 func main() {
-
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
-
-	fmt.Println()
-
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
-
+	runner.Main(newDay())
 }
@@ -2,9 +2,6 @@ module 08
 
 go 1.25
 
-require (
-	github.com/chewxy/math32 v1.11.1 // indirect
-	github.com/dominikbraun/graph v0.23.0 // indirect
-	github.com/golang/geo v0.0.0-20251125140653-09e2dd3603dd // indirect
-	github.com/ungerik/go3d v0.0.0-20251020194721-1bde1320d420 // indirect
-)
+require aoc v0.0.0
+
+replace aoc => ../aoc
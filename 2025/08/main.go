@@ -4,25 +4,22 @@ import (
 	"bufio"
 	"fmt"
 	"log"
-	"os"
+	"math/rand/v2"
 	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/dominikbraun/graph"
-	"github.com/golang/geo/r3"
+	"aoc/geom"
+	"aoc/graph"
+	"aoc/input"
+	"aoc/render"
+	"aoc/runner"
 )
 
-type myVector struct {
-	vector r3.Vector
-	name   int
-}
-
-func readFile(filename string) []myVector {
-	var result []myVector
-	counter := 0
+func readFile(filename string) []geom.Point3 {
+	var result []geom.Point3
 
-	file, err := os.Open(filename)
+	file, err := input.Open(filename)
 	if err != nil {
 		log.Fatalf("failed to open file: %s", err)
 	}
@@ -33,23 +30,22 @@ func readFile(filename string) []myVector {
 	for scanner.Scan() {
 		line := scanner.Text()
 		coordinates := strings.Split(line, ",")
-		x, err := strconv.ParseFloat(coordinates[0], 32)
+		x, err := strconv.Atoi(coordinates[0])
 		if err != nil {
 			log.Fatalf("failed to convert x coordinate: %s", err)
 		}
 
-		y, err := strconv.ParseFloat(coordinates[1], 32)
+		y, err := strconv.Atoi(coordinates[1])
 		if err != nil {
 			log.Fatalf("failed to convert y coordinate: %s", err)
 		}
 
-		z, err := strconv.ParseFloat(coordinates[2], 32)
+		z, err := strconv.Atoi(coordinates[2])
 		if err != nil {
 			log.Fatalf("failed to convert z coordinate: %s", err)
 		}
 
-		result = append(result, myVector{name: counter, vector: r3.Vector{x, y, z}})
-		counter++
+		result = append(result, geom.Point3{X: x, Y: y, Z: z})
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -59,40 +55,23 @@ func readFile(filename string) []myVector {
 	return result
 }
 
-func calculateDistance(a, b myVector) float64 {
-
-	//return vec3.Distance(&a.vector, &b.vector)
-	//return vec3.SquareDistance(&a.vector, &b.vector)
-
-	//Calculate Euclidean distance between two 3D points
-	x1, y1, z1 := a.vector.X, a.vector.Y, a.vector.Z
-	x2, y2, z2 := b.vector.X, b.vector.Y, b.vector.Z
-
-	//Calculate the sum of squared differences
-	sum_sq_diff := (x2-x1)*(x2-x1) + (y2-y1)*(y2-y1) + (z2-z1)*(z2-z1)
-
-	//Take the square root
-	distance := sum_sq_diff
-
-	return distance
-
-}
-
+// vectorDistance connects two junction boxes, identified by their index in the input.
+// The squared Euclidean distance orders pairs exactly like the real distance.
 type vectorDistance struct {
-	from     myVector
-	to       myVector
-	distance float64
+	from     int
+	to       int
+	distance int
 }
 
-func calculateDistances(points []myVector) []vectorDistance {
+func calculateDistances(points []geom.Point3) []vectorDistance {
 	var distances []vectorDistance
 
 	for i := 0; i < len(points); i++ {
 
 		for j := i + 1; j < len(points); j++ {
 
-			distance := calculateDistance(points[i], points[j])
-			distanceObj := vectorDistance{from: points[i], to: points[j], distance: distance}
+			distance := points[i].SquaredDistance(points[j])
+			distanceObj := vectorDistance{from: i, to: j, distance: distance}
 			distances = append(distances, distanceObj)
 
 		}
@@ -103,37 +82,38 @@ func calculateDistances(points []myVector) []vectorDistance {
 }
 
 func sortDistances(distances []vectorDistance) {
-	sort.Slice(distances, func(i, j int) bool {
+	sort.SliceStable(distances, func(i, j int) bool {
 		return distances[i].distance < distances[j].distance
 	})
 }
 
-func createGraph(nodes []myVector) graph.Graph[int, int] {
-	g := graph.New(graph.IntHash)
+func createGraph(nodes []geom.Point3) *graph.Graph[int] {
+	g := graph.New[int]()
 
-	for _, node := range nodes {
-		err := g.AddVertex(node.name)
-		if err != nil {
-			return nil
-		}
+	for name := range nodes {
+		g.AddVertex(name)
 	}
 
 	return g
 }
 
-func connectGraph(g graph.Graph[int, int], connections []vectorDistance) graph.Graph[int, int] {
+// defaultConnectionCount is how many of the closest pairs the first task connects, unless
+// the connections option says otherwise. The puzzle's example uses 10.
+const defaultConnectionCount = 1000
+
+func connectGraph(g *graph.Graph[int], connections []vectorDistance, connectionCount int) *graph.Graph[int] {
 	counter := 0
 	for _, connection := range connections {
 		first := connection.from
 		second := connection.to
 
-		err := g.AddEdge(first.name, second.name)
+		err := g.AddEdge(first, second)
 		if err != nil {
-			return nil
+			log.Fatal("Failed to add edge: ", err)
 		}
 		counter++
 
-		if counter >= 1000 {
+		if counter >= connectionCount {
 			break
 		}
 	}
@@ -141,36 +121,12 @@ func connectGraph(g graph.Graph[int, int], connections []vectorDistance) graph.G
 	return g
 }
 
-func findConnectedComponents(g graph.Graph[int, int], nodes []myVector) [][]int {
-	var components [][]int
-	visited := make(map[int]bool)
-
-	for _, node := range nodes {
-		var component []int
-
-		if visited[node.name] {
-			continue
-		}
-
-		graph.DFS(g, node.name, func(value int) bool {
-			if !visited[value] {
-				visited[value] = true
-				component = append(component, value)
-			}
-			return false
-		})
-
-		if len(component) > 0 {
-			components = append(components, component)
-		}
-	}
-
-	return components
-
+func findConnectedComponents(g *graph.Graph[int]) [][]int {
+	return g.ConnectedComponents()
 }
 
 func sortComponentsBySize(components [][]int) {
-	sort.Slice(components, func(i, j int) bool {
+	sort.SliceStable(components, func(i, j int) bool {
 		return len(components[i]) > len(components[j])
 	})
 }
@@ -189,10 +145,10 @@ func calculateCircuitSize(component [][]int) int {
 	return size
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	nodes := readFile(filename)
+	nodes := readFile(run.Input)
 
 	connections := calculateDistances(nodes)
 
@@ -200,26 +156,63 @@ func solveFirst(filename string) {
 
 	myGraph := createGraph(nodes)
 
-	connectedGraph := connectGraph(myGraph, connections)
+	connectedGraph := connectGraph(myGraph, connections, run.Options.Int("connections", defaultConnectionCount))
 
-	components := findConnectedComponents(connectedGraph, nodes)
+	components := findConnectedComponents(connectedGraph)
 
 	sortComponentsBySize(components)
 
 	circuitSize := calculateCircuitSize(components)
 
-	fmt.Println("Circuit size: ", circuitSize)
+	fmt.Fprintln(run.Out, "Circuit size: ", circuitSize)
 
 	//file, _ := os.Create("./mygraph.gv")
-	//_ = draw.DOT(connectedGraph, file)
+	//_ = connectedGraph.WriteDOT(file, nil)
+
+	fmt.Fprintln(run.Out)
+
+	if run.Drawing != nil {
+		drawCircuits(run.Drawing, nodes, connectedGraph, components)
+	}
 
-	fmt.Println()
+	return circuitSize
 }
 
-func areAllNodesConnected(g graph.Graph[int, int], nodesCount int) bool {
+// drawCircuits draws the junction boxes and their connections seen from above,
+// dropping the Z coordinate. The three largest circuits get their own colours.
+func drawCircuits(drawing *render.SVG, nodes []geom.Point3, g *graph.Graph[int], components [][]int) {
+	colours := make([]string, len(nodes))
+	for rank, component := range components {
+		colour := "#adb5bd"
+		if rank < 3 {
+			colour = render.PaletteColour(rank)
+		}
+		for _, node := range component {
+			colours[node] = colour
+		}
+	}
+
+	project := func(p geom.Point3) geom.Point2 {
+		return geom.Point2{X: p.X, Y: p.Y}
+	}
+
+	for _, from := range g.Vertices() {
+		for _, to := range g.Neighbours(from) {
+			// Undirected edges are listed from both ends, so draw each once
+			if from < to {
+				drawing.Line(project(nodes[from]), project(nodes[to]), render.Style{Stroke: colours[from]})
+			}
+		}
+	}
+	for node, position := range nodes {
+		drawing.Marker(project(position), 3, render.Style{Fill: colours[node]})
+	}
+}
+
+func areAllNodesConnected(g *graph.Graph[int], nodesCount int) bool {
 	connectedNodesCount := 0
 
-	graph.BFS(g, 0, func(value int) bool {
+	g.BFS(0, func(value int) bool {
 		connectedNodesCount++
 		return false
 
@@ -231,19 +224,19 @@ func areAllNodesConnected(g graph.Graph[int, int], nodesCount int) bool {
 	return false
 }
 
-func connectFullGraph(g graph.Graph[int, int], connections []vectorDistance, nodesCount int) int {
+func connectFullGraph(g *graph.Graph[int], connections []vectorDistance, nodes []geom.Point3) int {
 	for _, connection := range connections {
 		first := connection.from
 		second := connection.to
 
-		err := g.AddEdge(first.name, second.name)
+		err := g.AddEdge(first, second)
 		if err != nil {
 			log.Fatal("Failed to add edge: ", err)
 			return 0
 		}
 
-		if areAllNodesConnected(g, nodesCount) {
-			return int(first.vector.X * second.vector.X)
+		if areAllNodesConnected(g, len(nodes)) {
+			return nodes[first].X * nodes[second].X
 		}
 
 	}
@@ -251,10 +244,10 @@ func connectFullGraph(g graph.Graph[int, int], connections []vectorDistance, nod
 	return 0
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	nodes := readFile(filename)
+	nodes := readFile(run.Input)
 
 	connections := calculateDistances(nodes)
 
@@ -262,20 +255,63 @@ func solveSecond(filename string) {
 
 	myGraph := createGraph(nodes)
 
-	result := connectFullGraph(myGraph, connections, len(nodes))
+	result := connectFullGraph(myGraph, connections, nodes)
 
-	fmt.Println("Result: ", result)
-	fmt.Println()
+	fmt.Fprintln(run.Out, "Result: ", result)
+	fmt.Fprintln(run.Out)
+
+	return result
 }
 
-func main() {
+// anonymize leaves out a few junction boxes, which changes the circuits and so both
+// answers, then permutes the axes of the others, scales them and shifts them. The scale
+// comes with a jitter smaller than it, so that no coordinate of the real input is kept
+// even up to a factor.
+func anonymize(lines []string, rng *rand.Rand, _ *runner.Options) []string {
+	var result []string
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+	dropped := make(map[int]bool)
+	for len(dropped) < max(1, len(lines)/100) && len(dropped) < len(lines) {
+		dropped[rng.IntN(len(lines))] = true
+	}
+	axes := rng.Perm(3)
+	scale := 2 + rng.IntN(3)
+	shifts := []int{rng.IntN(10000), rng.IntN(10000), rng.IntN(10000)}
 
-	fmt.Println()
+	for i, line := range lines {
+		coordinates := strings.Split(line, ",")
+		if len(coordinates) != 3 {
+			log.Fatalf("invalid junction box: %s", line)
+		}
+		if dropped[i] {
+			continue
+		}
 
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+		moved := make([]string, 3)
+		for j, axis := range axes {
+			value, err := strconv.Atoi(coordinates[axis])
+			if err != nil {
+				log.Fatalf("failed to convert coordinate: %s", err)
+			}
+			moved[j] = strconv.Itoa(value*scale + rng.IntN(scale) + shifts[j])
+		}
+		result = append(result, strings.Join(moved, ","))
+	}
 
+	return result
+}
+
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+		Anonymize: anonymize,
+	}
+}
+
+func main() {
+	runner.Main(newDay())
 }
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"aoc/runner/runnertest"
+)
+
+func TestAnswers(t *testing.T) {
+	runnertest.Answers(t, newDay())
+}
+
+func TestDeterministic(t *testing.T) {
+	runnertest.Deterministic(t, newDay())
+}
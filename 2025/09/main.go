@@ -3,29 +3,29 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
-	"math"
-	"os"
+	"math/rand/v2"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
-)
 
-// Represent green tiles using polygon edges and interior detection
-type TileSet struct {
-	edges   [][4]int // List of line segments [x1,y1,x2,y2]
-	polygon [][]int  // The polygon vertices in order
-}
+	"aoc/geom"
+	"aoc/input"
+	"aoc/render"
+	"aoc/runner"
+)
 
 type Pair struct {
 	i, j        int
-	maxPossible float64
+	maxPossible int
 }
 
-func readFile(filename string) [][]int {
-	var result [][]int
+func readFile(filename string) geom.Polygon {
+	var result geom.Polygon
 
-	file, err := os.Open(filename)
+	file, err := input.Open(filename)
 	if err != nil {
 		log.Fatalf("failed to open file: %s", err)
 	}
@@ -33,12 +33,14 @@ func readFile(filename string) [][]int {
 
 	scanner := bufio.NewScanner(file)
 
+	number := 0
 	for scanner.Scan() {
-		line := scanner.Text()
-		chars := strings.Split(line, ",")
-		x, _ := strconv.Atoi(chars[0])
-		y, _ := strconv.Atoi(chars[1])
-		result = append(result, []int{x, y})
+		number++
+		tile, err := parseTile(scanner.Text())
+		if err != nil {
+			log.Fatalf("%s:%d: %s", filename, number, err)
+		}
+		result = append(result, tile)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -47,127 +49,66 @@ func readFile(filename string) [][]int {
 
 	return result
 }
-func calcRectangleSize(x1, y1, x2, y2 int) float64 {
-	width := math.Abs(float64(x2 - x1))
-	height := math.Abs(float64(y2 - y1))
-	return (width + 1) * (height + 1)
-}
 
-func findBiggestRectangle(redTiles [][]int) ([4]int, float64) {
-	var maxSize float64 = 0
-	var coords [4]int
+func findBiggestRectangle(redTiles []geom.Point2) (geom.Rect, int) {
+	maxSize := 0
+	var biggest geom.Rect
 	for i := 0; i < len(redTiles); i++ {
 		for j := i + 1; j < len(redTiles); j++ {
-			x1, y1 := redTiles[i][0], redTiles[i][1]
-			x2, y2 := redTiles[j][0], redTiles[j][1]
-
-			size := calcRectangleSize(x1, y1, x2, y2)
+			rect := geom.RectFromCorners(redTiles[i], redTiles[j])
 
-			if size > maxSize {
+			if size := rect.Area(); size > maxSize {
 				maxSize = size
-				coords = [4]int{x1, y1, x2, y2}
+				biggest = rect
 			}
 		}
 	}
 
-	return coords, maxSize
+	return biggest, maxSize
 
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	redTiles := readFile(filename)
-	coords, size := findBiggestRectangle(redTiles)
-	fmt.Printf("Biggest rectangle coordinates: (%d, %d) to (%d, %d) "+
-		"with size %.0f\n", coords[0], coords[1], coords[2], coords[3], size)
+	redTiles := readFile(run.Input)
+	rect, size := findBiggestRectangle(redTiles)
+	fmt.Fprintf(run.Out, "Biggest rectangle coordinates: (%v) to (%v) "+
+		"with size %d\n", rect.Min, rect.Max, size)
 
-	fmt.Println()
-}
+	fmt.Fprintln(run.Out)
 
-func buildGreenTileSet(redTiles [][]int) *TileSet {
-	ts := &TileSet{
-		edges:   make([][4]int, 0),
-		polygon: redTiles,
+	if run.Drawing != nil {
+		drawTiles(run.Drawing, redTiles, rect)
 	}
 
-	// Build edges by connecting consecutive red tiles
-	for i := 0; i < len(redTiles); i++ {
-		curr := redTiles[i]
-		next := redTiles[(i+1)%len(redTiles)] // wrap around
-
-		ts.edges = append(ts.edges, [4]int{curr[0], curr[1], next[0], next[1]})
-	}
-
-	return ts
-}
-
-// Check if point is on an edge
-func (ts *TileSet) isOnEdge(x, y int) bool {
-	for _, edge := range ts.edges {
-		x1, y1, x2, y2 := edge[0], edge[1], edge[2], edge[3]
-
-		// Check if point is on horizontal edge
-		if y1 == y2 && y == y1 {
-			minX := int(math.Min(float64(x1), float64(x2)))
-			maxX := int(math.Max(float64(x1), float64(x2)))
-			if x >= minX && x <= maxX {
-				return true
-			}
-		}
-
-		// Check if point is on vertical edge
-		if x1 == x2 && x == x1 {
-			minY := int(math.Min(float64(y1), float64(y2)))
-			maxY := int(math.Max(float64(y1), float64(y2)))
-			if y >= minY && y <= maxY {
-				return true
-			}
-		}
-	}
-	return false
+	return size
 }
 
-// Check if point is inside the polygon
-func (ts *TileSet) isInsidePolygon(x, y int) bool {
-	n := len(ts.polygon)
-	inside := false
-
-	j := n - 1
-	for i := 0; i < n; i++ {
-		xi, yi := ts.polygon[i][0], ts.polygon[i][1]
-		xj, yj := ts.polygon[j][0], ts.polygon[j][1]
-
-		if ((yi > y) != (yj > y)) && (x < (xj-xi)*(y-yi)/(yj-yi)+xi) {
-			inside = !inside
-		}
-		j = i
+// drawTiles draws the green tile polygon with its red corner tiles and the chosen rectangle.
+func drawTiles(drawing *render.SVG, redTiles geom.Polygon, rect geom.Rect) {
+	drawing.Polygon(redTiles, render.Style{Fill: "#8ac926", Stroke: "#2b9348", Opacity: 0.6})
+	for _, tile := range redTiles {
+		drawing.Marker(tile, 2, render.Style{Fill: "#e63946"})
 	}
-
-	return inside
-}
-
-// Checks if a given point (x, y) is either on the edge or inside the polygon of the TileSet.
-func (ts *TileSet) contains(x, y int) bool {
-	return ts.isOnEdge(x, y) || ts.isInsidePolygon(x, y)
+	drawing.Rect(rect.Min, rect.Max, render.Style{Stroke: "#1982c4", StrokeWidth: 3})
 }
 
-func isRectanglePossible(ts *TileSet, x1, y1, x2, y2 int) bool {
-	// Check whether the rectangle defined by (x1, y1) and (x2, y2)
-	// contains only green tiles (tiles in tileset)
-	minX := int(math.Min(float64(x1), float64(x2)))
-	maxX := int(math.Max(float64(x1), float64(x2)))
-	minY := int(math.Min(float64(y1), float64(y2)))
-	maxY := int(math.Max(float64(y1), float64(y2)))
+// fullyCheckedArea is the largest rectangle isRectanglePossible checks tile by tile;
+// larger ones are sampled, so it may accept them wrongly.
+const fullyCheckedArea = 10000
 
-	width := maxX - minX + 1
-	height := maxY - minY + 1
+func isRectanglePossible(greenTiles geom.Polygon, rect geom.Rect) bool {
+	// Check whether the rectangle contains only green tiles
+	// (tiles on the edge of the polygon or inside it)
+	width := rect.Width()
+	height := rect.Height()
 
 	// For smaller rectangles, check all points
-	if width*height <= 10000 {
-		for y := minY; y <= maxY; y++ {
-			for x := minX; x <= maxX; x++ {
-				if !ts.contains(x, y) {
+	if width*height <= fullyCheckedArea {
+		for y := rect.Min.Y; y <= rect.Max.Y; y++ {
+			for x := rect.Min.X; x <= rect.Max.X; x++ {
+				if !greenTiles.Contains(geom.Point2{X: x, Y: y}) {
 					return false
 				}
 			}
@@ -177,37 +118,35 @@ func isRectanglePossible(ts *TileSet, x1, y1, x2, y2 int) bool {
 
 	// For larger rectangles, we need a different strategy
 	// Check if all 4 corners are inside
-	if !ts.contains(minX, minY) || !ts.contains(maxX, maxY) {
-		return false
-	}
-	if !ts.contains(minX, maxY) || !ts.contains(maxX, minY) {
-		return false
+	for _, corner := range rect.Corners() {
+		if !greenTiles.Contains(corner) {
+			return false
+		}
 	}
 
 	// Check all 4 edges comprehensively
 	// Top and bottom edges
-	for x := minX; x <= maxX; x++ {
-		if !ts.contains(x, minY) || !ts.contains(x, maxY) {
+	for x := rect.Min.X; x <= rect.Max.X; x++ {
+		if !greenTiles.Contains(geom.Point2{X: x, Y: rect.Min.Y}) ||
+			!greenTiles.Contains(geom.Point2{X: x, Y: rect.Max.Y}) {
 			return false
 		}
 	}
 
 	// Left and right edges
-	for y := minY; y <= maxY; y++ {
-		if !ts.contains(minX, y) || !ts.contains(maxX, y) {
+	for y := rect.Min.Y; y <= rect.Max.Y; y++ {
+		if !greenTiles.Contains(geom.Point2{X: rect.Min.X, Y: y}) ||
+			!greenTiles.Contains(geom.Point2{X: rect.Max.X, Y: y}) {
 			return false
 		}
 	}
 
 	// Sample interior more carefully - check multiple rows and columns
-	sampleStep := int(math.Max(float64(width)/20, float64(height)/20))
-	if sampleStep < 1 {
-		sampleStep = 1
-	}
+	sampleStep := max(width/20, height/20, 1)
 
-	for y := minY; y <= maxY; y += sampleStep {
-		for x := minX; x <= maxX; x += sampleStep {
-			if !ts.contains(x, y) {
+	for y := rect.Min.Y; y <= rect.Max.Y; y += sampleStep {
+		for x := rect.Min.X; x <= rect.Max.X; x += sampleStep {
+			if !greenTiles.Contains(geom.Point2{X: x, Y: y}) {
 				return false
 			}
 		}
@@ -216,84 +155,285 @@ func isRectanglePossible(ts *TileSet, x1, y1, x2, y2 int) bool {
 	return true
 }
 
-func findBiggestAppropriateRectangle(redTiles [][]int, ts *TileSet) ([4]int, float64) {
-	var maxSize float64 = 0
-	var coords [4]int
+// findBiggestAppropriateRectangle finds the biggest rectangle between two red tiles
+// that holds only green tiles, according to isGreen.
+func findBiggestAppropriateRectangle(w io.Writer, greenTiles geom.Polygon, isGreen func(geom.Rect) bool) (geom.Rect, int) {
+	maxSize := 0
+	var biggest geom.Rect
 
-	n := len(redTiles)
+	n := len(greenTiles)
 
 	// Optimization: Sort by potential area contribution and check most promising pairs first
 
-	fmt.Println("Generating candidate pairs...")
+	fmt.Fprintln(w, "Generating candidate pairs...")
 	candidates := make([]Pair, 0, n*n/2)
 
 	for i := 0; i < n; i++ {
 		for j := i + 1; j < n; j++ {
-			x1, y1 := redTiles[i][0], redTiles[i][1]
-			x2, y2 := redTiles[j][0], redTiles[j][1]
-			size := calcRectangleSize(x1, y1, x2, y2)
+			size := geom.RectFromCorners(greenTiles[i], greenTiles[j]).Area()
 			candidates = append(candidates, Pair{i, j, size})
 		}
 	}
 
 	// Sort candidates by size descending
-	fmt.Printf("Sorting %d candidates by size...\n", len(candidates))
-	sort.Slice(candidates, func(i, j int) bool {
+	fmt.Fprintf(w, "Sorting %d candidates by size...\n", len(candidates))
+	sort.SliceStable(candidates, func(i, j int) bool {
 		return candidates[i].maxPossible > candidates[j].maxPossible
 	})
 
-	fmt.Println("Checking candidates in order of decreasing size...")
+	fmt.Fprintln(w, "Checking candidates in order of decreasing size...")
 	checked := 0
 	for _, pair := range candidates {
 		checked++
 		if checked%10000 == 0 {
-			fmt.Printf("Checked %d/%d candidates, current max: %.0f\n", checked, len(candidates), maxSize)
+			fmt.Fprintf(w, "Checked %d/%d candidates, current max: %d\n", checked, len(candidates), maxSize)
 		}
 
 		// Early termination: if no remaining candidate can beat current max, stop
 		if pair.maxPossible <= maxSize {
-			fmt.Printf("Early termination at %d/%d candidates\n", checked, len(candidates))
+			fmt.Fprintf(w, "Early termination at %d/%d candidates\n", checked, len(candidates))
 			break
 		}
 
-		x1, y1 := redTiles[pair.i][0], redTiles[pair.i][1]
-		x2, y2 := redTiles[pair.j][0], redTiles[pair.j][1]
+		rect := geom.RectFromCorners(greenTiles[pair.i], greenTiles[pair.j])
 
-		if isRectanglePossible(ts, x1, y1, x2, y2) {
+		if isGreen(rect) {
 			maxSize = pair.maxPossible
-			coords = [4]int{x1, y1, x2, y2}
-			fmt.Printf("Found valid rectangle with size %.0f\n", maxSize)
+			biggest = rect
+			fmt.Fprintf(w, "Found valid rectangle with size %d\n", maxSize)
 		}
 	}
 
-	return coords, maxSize
+	return biggest, maxSize
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
-
+// readPolygon reads the red tiles, which are the vertices of a rectilinear polygon
+// whose edges and interior are the green tiles.
+func readPolygon(w io.Writer, filename string) geom.Polygon {
 	redTiles := readFile(filename)
-	fmt.Printf("Read %d red tiles\n", len(redTiles))
+	fmt.Fprintf(w, "Read %d red tiles\n", len(redTiles))
+	if !redTiles.IsRectilinear() {
+		log.Fatalf("red tiles do not form a rectilinear polygon")
+	}
+	return redTiles
+}
+
+// solveSecond checks rectangles exactly against the edges of the polygon.
+// isRectanglePossible samples the tiles of large rectangles instead, which costs more
+// per rectangle than the exact check on every input and may accept wrong ones, so it
+// is left to the rect command.
+func solveSecond(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	fmt.Println("Building green tile set...")
-	ts := buildGreenTileSet(redTiles)
-	fmt.Printf("Green tile set built\n")
+	redTiles := readPolygon(run.Out, run.Input)
+	fmt.Fprintln(run.Out, "Finding biggest appropriate rectangle...")
+	rect, size := findBiggestAppropriateRectangle(run.Out, redTiles, redTiles.ContainsRect)
 
-	fmt.Println("Finding biggest appropriate rectangle...")
-	coords, size := findBiggestAppropriateRectangle(redTiles, ts)
+	fmt.Fprintf(run.Out, "Biggest rectangle coordinates: (%v) to (%v) "+
+		"with size %d\n", rect.Min, rect.Max, size)
+
+	if run.Drawing != nil {
+		drawTiles(run.Drawing, redTiles, rect)
+	}
 
-	fmt.Printf("Biggest rectangle coordinates: (%d, %d) to (%d, %d) "+
-		"with size %.0f\n", coords[0], coords[1], coords[2], coords[3], size)
+	return size
 }
 
-func main() {
+// anonymize moves the red tiles by stretching or shrinking the gaps between distinct
+// coordinates on each axis. Tiles keep their order along both axes, so the polygon
+// keeps its shape, but every rectangle changes size. Gaps of one or two tiles are kept
+// because they decide which tiles touch.
+func anonymize(lines []string, rng *rand.Rand, _ *runner.Options) []string {
+	var result []string
+
+	var xs, ys []int
+	for _, tile := range parseTiles(lines) {
+		xs = append(xs, tile.X)
+		ys = append(ys, tile.Y)
+	}
+	remapX, remapY := remapAxis(xs, rng), remapAxis(ys, rng)
+
+	for _, tile := range parseTiles(lines) {
+		result = append(result, fmt.Sprintf("%d,%d", remapX[tile.X], remapY[tile.Y]))
+	}
+
+	return result
+}
+
+func parseTiles(lines []string) []geom.Point2 {
+	var tiles []geom.Point2
+	for i, line := range lines {
+		tile, err := parseTile(line)
+		if err != nil {
+			log.Fatalf("line %d: %s", i+1, err)
+		}
+		tiles = append(tiles, tile)
+	}
+	return tiles
+}
+
+// parseTile parses a tile written as x,y.
+func parseTile(s string) (geom.Point2, error) {
+	chars := strings.Split(s, ",")
+	if len(chars) != 2 {
+		return geom.Point2{}, fmt.Errorf("invalid tile %q, expected x,y", s)
+	}
+	x, errX := strconv.Atoi(chars[0])
+	y, errY := strconv.Atoi(chars[1])
+	if errX != nil || errY != nil {
+		return geom.Point2{}, fmt.Errorf("invalid tile %q, expected x,y", s)
+	}
+	return geom.Point2{X: x, Y: y}, nil
+}
+
+// remapAxis maps each distinct value to a new one, scaling every gap between neighbouring
+// values by 90% to 110%.
+func remapAxis(values []int, rng *rand.Rand) map[int]int {
+	slices.Sort(values)
+	values = slices.Compact(values)
+
+	remap := make(map[int]int, len(values))
+	for i, value := range values {
+		if i == 0 {
+			remap[value] = value
+			continue
+		}
+		gap := value - values[i-1]
+		if gap > 2 {
+			gap = max(gap*(90+rng.IntN(21))/100, 3)
+		}
+		remap[value] = remap[values[i-1]] + gap
+	}
+	return remap
+}
+
+// lint checks that the red tiles are the corners of a closed rectilinear polygon:
+// each tile is in line with the next, the last one with the first, and the edges only
+// meet at the corners they share.
+func lint(lines []string, _ *runner.Options) []runner.Issue {
+	var issues []runner.Issue
+
+	var tiles geom.Polygon
+	var tileLines []int
+	seen := map[geom.Point2]int{}
+	for i, line := range lines {
+		tile, err := parseTile(line)
+		if err != nil {
+			issues = append(issues, runner.Issuef(i+1, "%s", err))
+			continue
+		}
+		if first, ok := seen[tile]; ok {
+			issues = append(issues, runner.Issuef(i+1, "tile %v repeats line %d", tile, first))
+			continue
+		}
+		seen[tile] = i + 1
+		tiles = append(tiles, tile)
+		tileLines = append(tileLines, i+1)
+	}
+	if len(tiles) < 4 {
+		return append(issues, runner.Issuef(0, "%d tiles cannot be the corners of a rectilinear polygon, which needs at least 4", len(tiles)))
+	}
+
+	n := len(tiles)
+	edges := slices.Collect(tiles.Edges())
+	for i, edge := range edges {
+		if edge.A.X != edge.B.X && edge.A.Y != edge.B.Y {
+			issues = append(issues, runner.Issuef(tileLines[(i+1)%n], "tile %v is not in line with tile %v on line %d", edge.B, edge.A, tileLines[i]))
+		}
+	}
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+	// Edges next to each other share a corner; any other contact means the polygon
+	// touches or crosses itself
+	for i := range n {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if edges[i].Intersects(edges[j]) {
+				issues = append(issues, runner.Issuef(tileLines[(j+1)%n], "the edge from %v to %v meets the edge from %v to %v",
+					edges[j].A, edges[j].B, edges[i].A, edges[i].B))
+			}
+		}
+	}
 
-	fmt.Println()
+	return issues
+}
 
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+// locations names where a tile lies relative to the polygon of green tiles.
+var locations = map[geom.Location]string{
+	geom.Outside:  "outside",
+	geom.Boundary: "on the boundary",
+	geom.Inside:   "inside",
+}
 
+// commands lets rectangles and tiles be checked against an input interactively.
+func commands(run *runner.Run) map[string]runner.Command {
+	redTiles := readFile(run.Input)
+
+	return map[string]runner.Command{
+		"rect": {
+			Args: "<x1,y1> <x2,y2>",
+			Help: "check the rectangle with these corners, by sampling and exactly as in part 2",
+			Run: func(w io.Writer, args []string) error {
+				if len(args) != 2 {
+					return fmt.Errorf("give two corners")
+				}
+				a, err := parseTile(args[0])
+				if err != nil {
+					return err
+				}
+				b, err := parseTile(args[1])
+				if err != nil {
+					return err
+				}
+
+				rect := geom.RectFromCorners(a, b)
+				fmt.Fprintf(w, "area %d, sampled check: %v", rect.Area(), isRectanglePossible(redTiles, rect))
+				if redTiles.IsRectilinear() {
+					fmt.Fprintf(w, ", exact check: %v", redTiles.ContainsRect(rect))
+				}
+				fmt.Fprintln(w)
+				return nil
+			},
+		},
+		"tile": {
+			Args: "<x,y>...",
+			Help: "tell whether tiles are inside the polygon of green tiles",
+			Run: func(w io.Writer, args []string) error {
+				for _, arg := range args {
+					tile, err := parseTile(arg)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(w, "%v: %s\n", tile, locations[redTiles.Locate(tile)])
+				}
+				return nil
+			},
+		},
+		"biggest": {
+			Help: "show the biggest rectangle between two red tiles, as in part 1",
+			Run: func(w io.Writer, args []string) error {
+				rect, size := findBiggestRectangle(redTiles)
+				fmt.Fprintf(w, "(%v) to (%v) with size %d\n", rect.Min, rect.Max, size)
+				return nil
+			},
+		},
+	}
+}
+
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+		Anonymize: anonymize,
+		REPL:      commands,
+		Lint:      lint,
+	}
+}
+
+func main() {
+	runner.Main(newDay())
 }
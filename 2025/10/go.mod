@@ -2,3 +2,6 @@ module 10
 
 go 1.25
 
+require aoc v0.0.0
+
+replace aoc => ../aoc
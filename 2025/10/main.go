@@ -1,184 +1,150 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
+	"io"
+	"iter"
 	"log"
-	"os"
-	"reflect"
-	"strconv"
-	"strings"
+	"math/big"
+
+	"aoc/linalg"
+	"aoc/parse"
+	"aoc/runner"
+	"aoc/search"
 )
 
+// lights is the desired state of the indicator lights, written like [.##.],
+// with 1 for a light that is on.
+type lights []int
+
+func (l *lights) UnmarshalText(text []byte) error {
+	*l = make(lights, len(text))
+	for i, c := range text {
+		switch c {
+		case '#':
+			(*l)[i] = 1
+		case '.':
+		default:
+			return fmt.Errorf("invalid light %q, expected '.' or '#'", c)
+		}
+	}
+	return nil
+}
+
+// button lists the counters, and lights, it affects, written like (1,3).
+type button struct {
+	Counters []int `prefix:"(" suffix:")" sep:","`
+}
+
+// machine is a line of the input: its lights, its buttons and its joltage requirements.
+type machine struct {
+	Lights   lights `prefix:"[" suffix:"] "`
+	Buttons  []button
+	Joltages []int `prefix:" {" suffix:"}" sep:","`
+}
+
+type manual struct {
+	Machines []machine `aoc:"lines"`
+}
+
 func readFile(filename string) ([][]int, [][][]int, [][]int) {
 	var machines [][]int
 	var buttons [][][]int
 	var requirements [][]int
 
-	file, err := os.Open(filename)
+	m, err := parse.File[manual](filename)
 	if err != nil {
-		log.Fatalf("failed to open file: %s", err)
+		log.Fatalf("%s", err)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, " ")
+	for _, machine := range m.Machines {
 		var lineButtons [][]int
-
-		for i, part := range parts {
-			partLen := len(part)
-			if i == 0 {
-				trimmed := part[1 : partLen-1]
-				var lineMachines []int
-				for character := range trimmed {
-					if trimmed[character] == '#' {
-						lineMachines = append(lineMachines, 1)
-					} else {
-						lineMachines = append(lineMachines, 0)
-					}
-				}
-				machines = append(machines, lineMachines)
-			} else if strings.HasPrefix(part, "(") {
-				value := part[1 : partLen-1]
-				numbers := strings.Split(value, ",")
-				var button []int
-				for _, numStr := range numbers {
-					number, err := strconv.Atoi(numStr)
-					if err != nil {
-						log.Fatalf("failed to convert string to int: %s", err)
-					}
-					button = append(button, number)
-				}
-
-				lineButtons = append(lineButtons, button)
-
-			} else if strings.HasPrefix(part, "{") {
-				value := part[1 : partLen-1]
-				numbers := strings.Split(value, ",")
-				var lineRequirements []int
-				for _, numStr := range numbers {
-					number, err := strconv.Atoi(numStr)
-					if err != nil {
-						log.Fatalf("failed to convert string to int: %s", err)
-					}
-					lineRequirements = append(lineRequirements, number)
-				}
-
-				requirements = append(requirements, lineRequirements)
-			}
-
+		for _, button := range machine.Buttons {
+			lineButtons = append(lineButtons, button.Counters)
 		}
 
+		machines = append(machines, machine.Lights)
 		buttons = append(buttons, lineButtons)
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("error reading file: %s", err)
+		requirements = append(requirements, machine.Joltages)
 	}
 
 	return machines, buttons, requirements
 }
 
-// Global variable to track the minimum button combination found
-var minButtonsCombination [][]int = nil
-
-func toggleMachinesState(machinesState []int, machine int) []int {
-	// Create a copy to avoid modifying the original state
-	newMachineState := make([]int, len(machinesState))
-	copy(newMachineState, machinesState)
-
-	if newMachineState[machine] == 1 {
-		newMachineState[machine] = 0
-	} else {
-		newMachineState[machine] = 1
-	}
-
-	return newMachineState
+// lightsState is the state of the indicator lights as a bitmask, together with
+// the button pressed to reach it (-1 for the initial state).
+type lightsState struct {
+	lights uint64
+	button int
 }
 
-// toggleButton applies a button press by toggling all affected indicator lights
-func toggleButton(machinesState []int, button []int) []int {
-	newMachineState := machinesState
-	for _, machine := range button {
-		newMachineState = toggleMachinesState(newMachineState, machine)
+// toMask converts a list of light indices into a bitmask.
+func toMask(lights []int) uint64 {
+	var mask uint64
+	for _, light := range lights {
+		mask |= 1 << light
 	}
-
-	return newMachineState
+	return mask
 }
 
-// tryButtonsCombinations uses depth-first search to find button combinations
-// that transform the machine from initial state (all lights off) to desired state.
-// This is a brute-force approach with depth limiting and pruning.
-func tryButtonsCombinations(desiredMachinesState []int, buttons [][]int, currentMachinesState []int, currentlyPressedButtons [][]int, maxDepth int) {
-	// Base case: check if we found a valid solution
-	if len(desiredMachinesState) == len(currentMachinesState) && reflect.DeepEqual(desiredMachinesState, currentMachinesState) {
-		if minButtonsCombination == nil || len(currentlyPressedButtons) < len(minButtonsCombination) {
-			// Make a copy of the solution
-			solution := make([][]int, len(currentlyPressedButtons))
-			copy(solution, currentlyPressedButtons)
-			minButtonsCombination = solution
+// findFewestButtonsCombination finds the minimum number of button presses needed
+// to configure the indicator lights to the desired state (Part 1 solution).
+// Uses iterative deepening: tries depth 1, then 2, then 3, etc. until solution found.
+// Pressing a button toggles all affected indicator lights.
+func findFewestButtonsCombination(w io.Writer, desiredMachinesState []int, buttons [][]int) [][]int {
+	var desired uint64
+	for machine, on := range desiredMachinesState {
+		if on == 1 {
+			desired |= 1 << machine
 		}
-		return
 	}
 
-	// Prune if we've already found a better solution
-	if minButtonsCombination != nil && len(currentlyPressedButtons) >= len(minButtonsCombination) {
-		return
+	buttonMasks := make([]uint64, len(buttons))
+	for i, button := range buttons {
+		buttonMasks[i] = toMask(button)
 	}
 
-	// Depth limit reached, stop exploring this branch
-	if len(currentlyPressedButtons) >= maxDepth {
-		return
+	result := search.IDDFS(search.Problem[lightsState, uint64]{
+		Start: lightsState{button: -1},
+		Successors: func(s lightsState) iter.Seq[lightsState] {
+			return func(yield func(lightsState) bool) {
+				for i, mask := range buttonMasks {
+					if !yield(lightsState{lights: s.lights ^ mask, button: i}) {
+						return
+					}
+				}
+			}
+		},
+		Goal: func(s lightsState) bool {
+			return s.lights == desired
+		},
+		// Reaching the same lights again is never better than the first time
+		Key: func(s lightsState) uint64 {
+			return s.lights
+		},
+	}, len(buttons)*2)
+
+	if !result.Found {
+		fmt.Fprintln(w, "No combination found")
+		return nil
 	}
 
-	// Try pressing each button and recursively explore
-	for i := 0; i < len(buttons); i++ {
-		button := buttons[i]
-		newMachinesState := toggleButton(currentMachinesState, button)
-		// Create a copy of currentlyPressedButtons to avoid shared state
-		newPressedButtons := make([][]int, len(currentlyPressedButtons)+1)
-		copy(newPressedButtons, currentlyPressedButtons)
-		newPressedButtons[len(currentlyPressedButtons)] = button
+	fmt.Fprintf(w, "Found after expanding %d states\n", result.Stats.Expanded)
 
-		tryButtonsCombinations(desiredMachinesState, buttons, newMachinesState, newPressedButtons, maxDepth)
+	pressedButtons := [][]int{}
+	for _, state := range result.Path[1:] {
+		pressedButtons = append(pressedButtons, buttons[state.button])
 	}
-
-	return
+	return pressedButtons
 }
 
-// findFewestButtonsCombination finds the minimum number of button presses needed
-// to configure the indicator lights to the desired state (Part 1 solution).
-// Uses iterative deepening: tries depth 1, then 2, then 3, etc. until solution found.
-func findFewestButtonsCombination(desiredMachinesState []int, buttons [][]int) [][]int {
-	minButtonsCombination = nil
-	currentMachinesState := make([]int, len(desiredMachinesState))
-	var currentlyPressedButtons [][]int
-
-	// Try increasing depths
-	for depth := 1; depth <= len(buttons)*2; depth++ {
-		fmt.Println("Trying depth: ", depth)
-
-		tryButtonsCombinations(desiredMachinesState, buttons, currentMachinesState, currentlyPressedButtons, depth)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-		if minButtonsCombination != nil {
-			return minButtonsCombination
-		}
-	}
+	machines, buttons, _ := readFile(run.Input)
 
-	fmt.Println("No combination found")
-	return nil
-}
-
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
-
-	machines, buttons, _ := readFile(filename)
-
-	fmt.Println("Machines: ", machines)
-	fmt.Println("Buttons: ", buttons)
+	fmt.Fprintln(run.Out, "Machines: ", machines)
+	fmt.Fprintln(run.Out, "Buttons: ", buttons)
 
 	totalButtonPresses := 0
 
@@ -186,241 +152,113 @@ func solveFirst(filename string) {
 		desiredMachinesState := machines[i]
 		buttonsForLine := buttons[i]
 
-		result := findFewestButtonsCombination(desiredMachinesState, buttonsForLine)
+		result := findFewestButtonsCombination(run.Out, desiredMachinesState, buttonsForLine)
 
-		fmt.Println("Result: ", result)
+		fmt.Fprintln(run.Out, "Result: ", result)
 		if result != nil {
 			totalButtonPresses += len(result)
 		}
-	}
-
-	fmt.Println("\nTotal button presses:", totalButtonPresses)
-
-}
-
-// gcd computes the greatest common divisor using Euclidean algorithm.
-// Used in Gaussian elimination to keep numbers manageable.
-func gcd(a, b int) int {
-	if a < 0 {
-		a = -a
-	}
-	if b < 0 {
-		b = -b
-	}
-	for b != 0 {
-		a, b = b, a%b
-	}
-	return a
-}
-
-// Matrix represents an augmented matrix for solving linear equations.
-// For part 2, each row is an equation like: button0*x0 + button1*x1 + ... = requirement
-// The last column holds the right-hand side (requirements).
-type Matrix struct {
-	rows int
-	cols int
-	data [][]int
-}
-
-func NewMatrix(rows, cols int) *Matrix {
-	data := make([][]int, rows)
-	for i := range data {
-		data[i] = make([]int, cols)
-	}
-	return &Matrix{rows: rows, cols: cols, data: data}
-}
-
-func (m *Matrix) Copy() *Matrix {
-	newM := NewMatrix(m.rows, m.cols)
-	for i := 0; i < m.rows; i++ {
-		copy(newM.data[i], m.data[i])
-	}
-	return newM
-}
-
-// GaussianElimination performs Gaussian elimination to row echelon form using integer arithmetic.
-// Uses GCD-based row operations to avoid fractions and keep all values as integers.
-// This transforms the matrix so we can identify free variables and solve the system.
-func (m *Matrix) GaussianElimination() {
-	pivotRow := 0
-
-	// Process each column to create row echelon form
-	for col := 0; col < m.cols-1 && pivotRow < m.rows; col++ {
-		// Find a pivot: a row with non-zero entry in this column
-		foundPivot := false
-		for row := pivotRow; row < m.rows; row++ {
-			if m.data[row][col] != 0 {
-				// Swap rows
-				m.data[pivotRow], m.data[row] = m.data[row], m.data[pivotRow]
-				foundPivot = true
-				break
-			}
-		}
-
-		if !foundPivot {
-			// No pivot in this column, it will be a free variable
-			continue
-		}
 
-		// Eliminate this column in all other rows using row operations
-		for row := 0; row < m.rows; row++ {
-			if row == pivotRow {
+		if run.Trace.Enabled() {
+			if result == nil {
+				run.Trace.Note("Machine %d: the lights %v cannot be reached", i+1, desiredMachinesState)
 				continue
 			}
-
-			if m.data[row][col] == 0 {
-				// Already zero, no need to eliminate
-				continue
+			machine := run.Trace.Section("Machine %d: lights %v need %d presses", i+1, desiredMachinesState, len(result))
+			for _, button := range result {
+				machine.Note("press the button toggling %v", button)
 			}
+		}
+	}
 
-			// Use GCD to determine multipliers that keep numbers small and avoid fractions
-			a := m.data[pivotRow][col]
-			b := m.data[row][col]
-			g := gcd(a, b)
-
-			mulPivot := b / g
-			mulRow := a / g
-
-			// Row operation: row = row * mulRow - pivotRow * mulPivot
-			// This eliminates the entry in column 'col' for this row
-			for c := 0; c < m.cols; c++ {
-				m.data[row][c] = m.data[row][c]*mulRow - m.data[pivotRow][c]*mulPivot
-			}
+	fmt.Fprintln(run.Out, "\nTotal button presses:", totalButtonPresses)
 
-			// Simplify the row by dividing by GCD if possible
-			rowGcd := 0
-			for c := 0; c < m.cols; c++ {
-				if m.data[row][c] != 0 {
-					if rowGcd == 0 {
-						rowGcd = m.data[row][c]
-						if rowGcd < 0 {
-							rowGcd = -rowGcd
-						}
-					} else {
-						rowGcd = gcd(rowGcd, m.data[row][c])
-					}
-				}
-			}
-			if rowGcd > 1 {
-				for c := 0; c < m.cols; c++ {
-					m.data[row][c] /= rowGcd
-				}
-			}
-		}
+	return totalButtonPresses
+}
 
-		pivotRow++
-	}
+// scaledSolution holds every solution of the button system, scaled to integers:
+// denominator * x = particular + sum(freeValues[k] * basis[k]).
+// The system is solved exactly with rational arithmetic, so elimination cannot overflow;
+// only the small scaled coefficients are used with machine integers during the search.
+type scaledSolution struct {
+	freeVars    []int
+	denominator int
+	particular  []int
+	basis       [][]int
 }
 
-// GetFreeVariables identifies which variables are free (can be set arbitrarily).
-// A free variable is one that doesn't have a leading 1 in its column after Gaussian elimination.
-func (m *Matrix) GetFreeVariables() []int {
-	freeVars := make([]bool, m.cols-1)
-	for i := range freeVars {
-		freeVars[i] = true
+func newScaledSolution(matrix *linalg.Matrix[*big.Rat], requirements []int64) *scaledSolution {
+	solution, err := matrix.Solve(matrix.Vector(requirements...))
+	if err != nil {
+		return nil
 	}
 
-	// Mark variables that are leading (not free)
-	for row := 0; row < m.rows; row++ {
-		// Find the first non-zero entry (leading variable) in this row
-		for col := 0; col < m.cols-1; col++ {
-			if m.data[row][col] != 0 {
-				// Check if this is the only non-zero entry in this column (excluding other rows)
-				onlyOne := true
-				for r := 0; r < m.rows; r++ {
-					if r != row && m.data[r][col] != 0 {
-						onlyOne = false
-						break
-					}
-				}
-				if onlyOne {
-					freeVars[col] = false
-				}
-				break
-			}
-		}
+	denominator, particular, basis := linalg.ScaleToIntegers(solution)
+	scaled := &scaledSolution{
+		freeVars:    solution.Free,
+		denominator: toInt(denominator),
+		particular:  make([]int, len(particular)),
+		basis:       make([][]int, len(basis)),
 	}
-
-	var result []int
-	for i, isFree := range freeVars {
-		if isFree {
-			result = append(result, i)
+	for i, value := range particular {
+		scaled.particular[i] = toInt(value)
+	}
+	for k, direction := range basis {
+		scaled.basis[k] = make([]int, len(direction))
+		for i, value := range direction {
+			scaled.basis[k][i] = toInt(value)
 		}
 	}
-	return result
+
+	return scaled
 }
 
-// SolveWithFreeVars solves the linear system given specific values for free variables.
-// Uses back-substitution to find values for all other variables.
-func (m *Matrix) SolveWithFreeVars(freeVars []int, freeValues []int) []int {
-	numVars := m.cols - 1
-	solution := make([]int, numVars)
-
-	// Initialize solution with the given free variable values
-	freeVarMap := make(map[int]int)
-	for i, varIdx := range freeVars {
-		solution[varIdx] = freeValues[i]
-		freeVarMap[varIdx] = freeValues[i]
+func toInt(value *big.Int) int {
+	if !value.IsInt64() {
+		log.Fatalf("coefficient %s does not fit in an int", value)
 	}
+	return int(value.Int64())
+}
 
-	// Solve for remaining (non-free) variables using back-substitution
-	for row := m.rows - 1; row >= 0; row-- {
-		// Find the leading variable
-		leadingVar := -1
-		for col := 0; col < numVars; col++ {
-			if m.data[row][col] != 0 {
-				leadingVar = col
-				break
-			}
-		}
-
-		// Empty row or already satisfied
-		if leadingVar == -1 {
-			continue
-		}
-
-		// Skip if this is a free variable (already set)
-		if _, isFree := freeVarMap[leadingVar]; isFree {
-			continue
-		}
+// solveWithFreeVars solves the linear system given specific values for free variables.
+// Returns nil if some button would have to be pressed a fractional number of times.
+func (s *scaledSolution) solveWithFreeVars(freeValues []int) []int {
+	solution := make([]int, len(s.particular))
 
-		// Calculate: leadingCoeff * x = rhs - sum(other terms)
-		rhs := m.data[row][numVars]
-		for col := 0; col < numVars; col++ {
-			if col != leadingVar {
-				rhs -= m.data[row][col] * solution[col]
-			}
+	for i := range solution {
+		value := s.particular[i]
+		for k, freeValue := range freeValues {
+			value += freeValue * s.basis[k][i]
 		}
 
-		leadingCoeff := m.data[row][leadingVar]
-
 		// Check if solution is integer (must divide evenly)
-		if rhs%leadingCoeff != 0 {
+		if value%s.denominator != 0 {
 			return nil
 		}
 
-		solution[leadingVar] = rhs / leadingCoeff
+		solution[i] = value / s.denominator
 	}
 
 	return solution
 }
 
 // findMinimalSolution finds the minimal number of button presses needed to satisfy
-// the joltage requirements.
-// Solves a system of linear equations using Gaussian elimination and searches over free variables.
-func findMinimalSolution(buttons [][]int, requirements []int) int {
+// the joltage requirements, and how many times each button is pressed.
+// Solves a system of linear equations exactly and searches over free variables.
+func findMinimalSolution(buttons [][]int, requirements []int) (int, []int) {
 	if len(buttons) == 0 || len(requirements) == 0 {
-		return 0
+		return 0, nil
 	}
 
 	numButtons := len(buttons)
 	numCounters := len(requirements)
 
-	// Build the augmented matrix for the system of equations
+	// Build the matrix for the system of equations
 	// Each row represents one counter: sum(button_presses[i] for buttons affecting this counter) = requirement
 	// Each column represents one button variable (how many times to press it)
-	// The last column is the right-hand side (the requirement value)
-	matrix := NewMatrix(numCounters, numButtons+1)
+	// The right-hand side holds the requirement values
+	matrix := linalg.NewRat(numCounters, numButtons)
+	rhs := make([]int64, numCounters)
 
 	// Populate the matrix based on which buttons affect which counters
 	for counter := 0; counter < numCounters; counter++ {
@@ -428,31 +266,32 @@ func findMinimalSolution(buttons [][]int, requirements []int) int {
 			// Check if this button affects this counter (coefficient is 1 if yes, 0 if no)
 			for _, affectedCounter := range buttons[button] {
 				if affectedCounter == counter {
-					matrix.data[counter][button] = 1
+					matrix.SetInt(counter, button, 1)
 					break
 				}
 			}
 		}
-		matrix.data[counter][numButtons] = requirements[counter]
+		rhs[counter] = int64(requirements[counter])
 	}
 
-	// Transform to row echelon form
-	matrix.GaussianElimination()
-
-	// Identify which button variables can be set freely
-	freeVars := matrix.GetFreeVariables()
+	// Solve exactly; the solution set is described by the buttons that can be set freely
+	system := newScaledSolution(matrix, rhs)
+	if system == nil {
+		return -1, nil
+	}
+	freeVars := system.freeVars
 
 	if len(freeVars) == 0 {
 		// No free variables: unique solution exists, solve directly
-		solution := matrix.SolveWithFreeVars(nil, nil)
+		solution := system.solveWithFreeVars(nil)
 		if solution == nil {
-			return -1
+			return -1, nil
 		}
 
 		// Verify all button presses are non-negative (can't press negative times)
 		for _, val := range solution {
 			if val < 0 {
-				return -1
+				return -1, nil
 			}
 		}
 
@@ -461,7 +300,7 @@ func findMinimalSolution(buttons [][]int, requirements []int) int {
 		for _, val := range solution {
 			total += val
 		}
-		return total
+		return total, solution
 	}
 
 	// Multiple solutions exist: search over free variable space to find minimum
@@ -474,13 +313,14 @@ func findMinimalSolution(buttons [][]int, requirements []int) int {
 	}
 
 	minPresses := -1
+	var bestSolution []int
 
 	// Recursive search function to try all combinations of free variable values
 	var searchFreeVars func(index int, values []int)
 	searchFreeVars = func(index int, values []int) {
 		if index == len(freeVars) {
 			// All free variables assigned, try solving with this combination
-			solution := matrix.SolveWithFreeVars(freeVars, values)
+			solution := system.solveWithFreeVars(values)
 			if solution == nil {
 				return
 			}
@@ -498,6 +338,7 @@ func findMinimalSolution(buttons [][]int, requirements []int) int {
 
 			if valid && (minPresses == -1 || total < minPresses) {
 				minPresses = total
+				bestSolution = solution
 			}
 			return
 		}
@@ -517,13 +358,13 @@ func findMinimalSolution(buttons [][]int, requirements []int) int {
 	freeValues := make([]int, len(freeVars))
 	searchFreeVars(0, freeValues)
 
-	return minPresses
+	return minPresses, bestSolution
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	_, buttons, requirements := readFile(filename)
+	_, buttons, requirements := readFile(run.Input)
 
 	totalButtonPresses := 0
 
@@ -531,26 +372,41 @@ func solveSecond(filename string) {
 		buttonsForLine := buttons[i]
 		requirementsForLine := requirements[i]
 
-		result := findMinimalSolution(buttonsForLine, requirementsForLine)
+		result, presses := findMinimalSolution(buttonsForLine, requirementsForLine)
 
 		if result > 0 {
 			totalButtonPresses += result
 		}
-	}
-
-	fmt.Println("Total button presses:", totalButtonPresses)
-}
-
-func main() {
 
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+		if run.Trace.Enabled() {
+			if result < 0 {
+				run.Trace.Note("Machine %d: the joltages %v cannot be reached", i+1, requirementsForLine)
+				continue
+			}
+			machine := run.Trace.Section("Machine %d: joltages %v need %d presses", i+1, requirementsForLine, result)
+			for button, count := range presses {
+				if count > 0 {
+					machine.Note("press the button for counters %v %d times", buttonsForLine[button], count)
+				}
+			}
+		}
+	}
 
-	fmt.Println()
+	fmt.Fprintln(run.Out, "Total button presses:", totalButtonPresses)
 
-	solveSecond("input1.txt")
-	solveSecond("input2.txt")
+	return totalButtonPresses
+}
 
-	fmt.Println()
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+		},
+	}
+}
 
+func main() {
+	runner.Main(newDay())
 }
@@ -2,4 +2,6 @@ module 11
 
 go 1.25
 
-require github.com/dominikbraun/graph v0.23.0 // indirect
+require aoc v0.0.0
+
+replace aoc => ../aoc
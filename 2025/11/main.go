@@ -2,12 +2,20 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
+	"maps"
+	"math/rand/v2"
+	"slices"
 	"strings"
 
-	"github.com/dominikbraun/graph"
+	"aoc/checked"
+	"aoc/graph"
+	"aoc/input"
+	"aoc/runner"
+	"aoc/trace"
 )
 
 // State represents the DP state: current node and which checkpoints have been visited.
@@ -23,7 +31,7 @@ type State struct {
 func readFile(filename string) map[string][]string {
 	connections := make(map[string][]string)
 
-	file, err := os.Open(filename)
+	file, err := input.Open(filename)
 	if err != nil {
 		log.Fatalf("failed to open file: %s", err)
 	}
@@ -47,81 +55,26 @@ func readFile(filename string) map[string][]string {
 	return connections
 }
 
-// topologicalSort performs Kahn's algorithm to order nodes so that
-// for every edge A -> B, A comes before B in the ordering.
-// Returns nil if the graph contains a cycle (not a DAG).
-func topologicalSort(connections map[string][]string) []string {
-	// Collect all nodes and count their incoming edges
-	nodes := make(map[string]bool)
-	inDegree := make(map[string]int)
-
-	for node, neighbors := range connections {
-		nodes[node] = true
-		for _, neighbor := range neighbors {
-			nodes[neighbor] = true
-			inDegree[neighbor]++ // neighbor has one more incoming edge
-		}
-	}
-
-	// Start with nodes that have no incoming edges (in-degree = 0)
-	var queue []string
-	for node := range nodes {
-		if inDegree[node] == 0 {
-			queue = append(queue, node)
-		}
-	}
-
-	var result []string
-	for len(queue) > 0 {
-		// Take next node with no remaining incoming edges
-		current := queue[0]
-		queue = queue[1:]
-		result = append(result, current)
-
-		// "Remove" this node by decrementing in-degree of its neighbors
-		for _, neighbor := range connections[current] {
-			inDegree[neighbor]--
-			if inDegree[neighbor] == 0 {
-				queue = append(queue, neighbor)
-			}
-		}
-	}
-
-	// If we couldn't process all nodes, there's a cycle
-	if len(result) != len(nodes) {
-		return nil // Cycle detected - not a DAG
-	}
-
-	return result
-}
-
 // countPathsDAG uses dynamic programming on a DAG to count valid paths.
 //   - Process nodes in reverse topological order (from 'out' back to 'svr')
 //   - For each node and each state (hasFft, hasDac), compute how many valid paths
 //     exist from that node to 'out'
 //   - A path is valid if it passes through 'fft' before 'dac'
-func countPathsDAG(connections map[string][]string, start, target string, topologicalOrder []string) int {
-
-	topoOrder := topologicalSort(connections)
-	if topoOrder == nil {
-		fmt.Println("Graph has a cycle - cannot use DP approach")
-		return -1
-	}
+func countPathsDAG(connections map[string][]string, start, target string, topologicalOrder []string) checked.Int {
 
 	// Reverse the order so we process from target ('out') backwards to start ('svr')
-	for i, j := 0, len(topoOrder)-1; i < j; i, j = i+1, j-1 {
-		topoOrder[i], topoOrder[j] = topoOrder[j], topoOrder[i]
-	}
+	topoOrder := slices.Clone(topologicalOrder)
+	slices.Reverse(topoOrder)
 
 	// dp[state] = number of valid paths from state.node to target,
-	dp := make(map[State]int)
+	dp := make(map[State]checked.Int)
 
 	// Base case: we're at the target node
 	// A path is only valid if we've visited BOTH fft and dac by the time we reach target
-	dp[State{target, true, true}] = 1   // valid: has both fft and dac
-	dp[State{target, true, false}] = 0  // invalid: missing dac
-	dp[State{target, false, true}] = 0  // invalid: missing fft
-	dp[State{target, false, false}] = 0 // invalid: missing both
+	dp[State{target, true, true}] = checked.NewInt(1)   // valid: has both fft and dac
+	dp[State{target, true, false}] = checked.NewInt(0)  // invalid: missing dac
+	dp[State{target, false, true}] = checked.NewInt(0)  // invalid: missing fft
+	dp[State{target, false, false}] = checked.NewInt(0) // invalid: missing both
 
 	// Process nodes in reverse topological order (from target back to start)
 	for _, node := range topoOrder {
@@ -141,15 +94,15 @@ func countPathsDAG(connections map[string][]string, start, target string, topolo
 
 				// if we're visiting 'dac', 'fft' must already be visited!
 				if node == "dac" && !fft {
-					dp[state] = 0
+					dp[state] = checked.NewInt(0)
 					continue
 				}
 
 				// Sum up paths through all neighbors
-				total := 0
+				total := checked.NewInt(0)
 				for _, neighbor := range connections[node] {
 					nextState := State{neighbor, newFft, newDac}
-					total += dp[nextState]
+					total = total.Add(dp[nextState])
 				}
 				dp[state] = total
 			}
@@ -190,29 +143,41 @@ func findAllPaths(connections map[string][]string, currentPath []string, visited
 
 }
 
-func solveFirst(filename string) {
-	fmt.Println("Solving first task with file: ", filename)
+func solveFirst(run *runner.Run) int {
+	fmt.Fprintln(run.Out, "Solving first task with file: ", run.Input)
 
-	connections := readFile(filename)
+	connections := readFile(run.Input)
 
-	startDevice := "you"
-	targetDevice := "out"
+	startDevice := run.Options.String("start", "you")
+	targetDevice := run.Options.String("target", "out")
 
 	visitedDevices := make(map[string]bool)
 	initialPath := []string{startDevice}
 
 	allPaths := findAllPaths(connections, initialPath, visitedDevices, targetDevice)
 
-	fmt.Println("Number of paths: ", len(allPaths))
-	fmt.Println()
+	fmt.Fprintln(run.Out, "Number of paths: ", len(allPaths))
+	fmt.Fprintln(run.Out)
+
+	if run.Trace.Enabled() {
+		paths := run.Trace.Section("%d paths lead from %s to %s", len(allPaths), startDevice, targetDevice)
+		for _, path := range allPaths {
+			paths.Note("%s", strings.Join(path, " -> "))
+		}
+	}
+
+	return len(allPaths)
 }
 
-func createGraph(connections map[string][]string) graph.Graph[string, string] {
-	g := graph.New(graph.StringHash, graph.Directed(), graph.PreventCycles())
+// createGraph builds the device graph. Devices are added in sorted order, since the
+// topological order breaks ties by insertion order and must not depend on map order.
+func createGraph(connections map[string][]string) *graph.Graph[string] {
+	g := graph.NewDirected[string]()
 
-	for from, tos := range connections {
+	for _, from := range slices.Sorted(maps.Keys(connections)) {
 		g.AddVertex(from)
-		for _, to := range tos {
+		for _, to := range connections[from] {
+			g.AddVertex(to)
 			g.AddEdge(from, to)
 		}
 	}
@@ -220,32 +185,307 @@ func createGraph(connections map[string][]string) graph.Graph[string, string] {
 	return g
 }
 
-func solveSecond(filename string) {
-	fmt.Println("Solving second task with file: ", filename)
+func solveSecond(run *runner.Run) checked.Int {
+	fmt.Fprintln(run.Out, "Solving second task with file: ", run.Input)
 
-	connections := readFile(filename)
+	connections := readFile(run.Input)
 
-	startDevice := "svr"
-	targetDevice := "out"
+	startDevice := run.Options.String("server", "svr")
+	targetDevice := run.Options.String("target", "out")
 
 	g := createGraph(connections)
 
-	//For a deterministic topological ordering, use StableTopologicalSort.
-	topologicalOrder, _ := graph.TopologicalSort(g)
-	fmt.Println("Topological order: ", topologicalOrder)
+	topologicalOrder, err := g.TopologicalSort()
+	if err != nil {
+		fmt.Fprintln(run.Out, "Graph has a cycle - cannot use DP approach: ", err)
+		return checked.NewInt(-1)
+	}
+	fmt.Fprintln(run.Out, "Topological order: ", topologicalOrder)
 
 	count := countPathsDAG(connections, startDevice, targetDevice, topologicalOrder)
 
-	fmt.Println("Number of paths: ", count)
-	fmt.Println()
+	fmt.Fprintln(run.Out, "Number of paths: ", count)
+	fmt.Fprintln(run.Out)
+
+	if run.Trace.Enabled() {
+		explainCheckpoints(run.Trace, connections, startDevice, targetDevice, count)
+	}
+
+	return count
 }
 
-func main() {
-	solveFirst("input1.txt")
-	solveFirst("input2.txt")
+// anonymize gives every device a new random name, except the ones the puzzle refers to,
+// and cuts a connection on a path from you to out and then one on a path from fft to
+// dac. Removing a connection keeps the graph acyclic, and removing one on a counted path
+// lowers the count, so both answers change.
+func anonymize(lines []string, rng *rand.Rand, _ *runner.Options) []string {
+	var result []string
+
+	names := map[string]string{"you": "you", "out": "out", "svr": "svr", "fft": "fft", "dac": "dac"}
+	used := make(map[string]bool)
+	for _, name := range names {
+		used[name] = true
+	}
+	rename := func(device string) string {
+		if name, ok := names[device]; ok {
+			return name
+		}
+		name := ""
+		for name == "" || used[name] {
+			name = string([]byte{byte('a' + rng.IntN(26)), byte('a' + rng.IntN(26)), byte('a' + rng.IntN(26))})
+		}
+		used[name] = true
+		names[device] = name
+		return name
+	}
 
-	fmt.Println()
+	connections := make(map[string][]string)
+	for _, line := range lines {
+		parts := strings.Split(line, " ")
+		first := parts[0]
+		connections[first[0:len(first)-1]] = parts[1:]
+	}
+	cut := make(map[[2]string]bool)
+	for _, leg := range [][2]string{{"you", "out"}, {"fft", "dac"}} {
+		edges := edgesOnPaths(connections, leg[0], leg[1], cut)
+		if len(edges) > 0 {
+			cut[edges[rng.IntN(len(edges))]] = true
+		}
+	}
 
-	solveSecond("input3.txt")
-	solveSecond("input2.txt")
+	for _, line := range lines {
+		parts := strings.Split(line, " ")
+		first := parts[0]
+		device := first[0 : len(first)-1]
+		renamed := []string{rename(device) + ":"}
+		for _, output := range parts[1:] {
+			if cut[[2]string{device, output}] {
+				continue
+			}
+			renamed = append(renamed, rename(output))
+		}
+		result = append(result, strings.Join(renamed, " "))
+	}
+
+	rng.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+
+	return result
+}
+
+// edgesOnPaths returns the connections, other than the cut ones, that some path from
+// start to target takes, sorted.
+func edgesOnPaths(connections map[string][]string, start, target string, cut map[[2]string]bool) [][2]string {
+	reverse := make(map[string][]string)
+	for device, outputs := range connections {
+		for _, output := range outputs {
+			if !cut[[2]string{device, output}] {
+				reverse[output] = append(reverse[output], device)
+			}
+		}
+	}
+	reach := func(from string, next func(string) []string) map[string]bool {
+		seen := map[string]bool{from: true}
+		stack := []string{from}
+		for len(stack) > 0 {
+			device := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, other := range next(device) {
+				if !seen[other] {
+					seen[other] = true
+					stack = append(stack, other)
+				}
+			}
+		}
+		return seen
+	}
+	fromStart := reach(start, func(device string) []string {
+		var outputs []string
+		for _, output := range connections[device] {
+			if !cut[[2]string{device, output}] {
+				outputs = append(outputs, output)
+			}
+		}
+		return outputs
+	})
+	toTarget := reach(target, func(device string) []string { return reverse[device] })
+
+	var edges [][2]string
+	for _, device := range slices.Sorted(maps.Keys(connections)) {
+		for _, output := range connections[device] {
+			edge := [2]string{device, output}
+			if fromStart[device] && toTarget[output] && !cut[edge] {
+				edges = append(edges, edge)
+			}
+		}
+	}
+	return edges
+}
+
+// requiredDevices are the devices the puzzle refers to, with their role.
+var requiredDevices = []struct{ name, role string }{
+	{"svr", "where part 2 starts"},
+	{"you", "where part 1 starts"},
+	{"fft", "which part 2 paths visit"},
+	{"dac", "which part 2 paths visit"},
+	{"out", "where every path ends"},
+}
+
+// lint checks that every line connects a device to its outputs, that no device is
+// listed twice, that the connections form no cycle and that every device the puzzle
+// refers to is present.
+func lint(lines []string, _ *runner.Options) []runner.Issue {
+	var issues []runner.Issue
+
+	connections := map[string][]string{}
+	defined := map[string]int{}
+	for i, line := range lines {
+		name, outputs, ok := strings.Cut(line, ": ")
+		devices := strings.Split(outputs, " ")
+		if !ok || !isDeviceName(name) || !slices.ContainsFunc(devices, isDeviceName) {
+			issues = append(issues, runner.Issuef(i+1, "%q is not a device and its outputs, like \"aaa: bbb ccc\"", line))
+			continue
+		}
+		if first, ok := defined[name]; ok {
+			issues = append(issues, runner.Issuef(i+1, "device %s is already connected on line %d", name, first))
+			continue
+		}
+		for _, device := range devices {
+			if !isDeviceName(device) {
+				issues = append(issues, runner.Issuef(i+1, "invalid output %q of device %s", device, name))
+			}
+		}
+		defined[name] = i + 1
+		connections[name] = slices.DeleteFunc(devices, func(device string) bool { return !isDeviceName(device) })
+	}
+
+	var cycle *graph.CycleError[string]
+	if _, err := createGraph(connections).TopologicalSort(); errors.As(err, &cycle) {
+		issues = append(issues, runner.Issuef(defined[cycle.Cycle[0]], "the devices form a cycle: %s", strings.Join(cycle.Cycle, " -> ")))
+	}
+
+	present := map[string]bool{}
+	for name, outputs := range connections {
+		present[name] = true
+		for _, device := range outputs {
+			present[device] = true
+		}
+	}
+	for _, device := range requiredDevices {
+		if !present[device.name] {
+			issues = append(issues, runner.Issuef(0, "there is no device %s, %s", device.name, device.role))
+		}
+	}
+
+	return issues
+}
+
+func isDeviceName(name string) bool {
+	return name != "" && strings.Trim(name, "abcdefghijklmnopqrstuvwxyz") == ""
+}
+
+// countPaths counts the paths from start to target, visiting each device once.
+func countPaths(connections map[string][]string, start, target string, memo map[string]checked.Int) checked.Int {
+	if start == target {
+		return checked.NewInt(1)
+	}
+	if count, ok := memo[start]; ok {
+		return count
+	}
+
+	total := checked.NewInt(0)
+	for _, next := range connections[start] {
+		total = total.Add(countPaths(connections, next, target, memo))
+	}
+	memo[start] = total
+	return total
+}
+
+// explainCheckpoints splits the paths through fft and then dac into their three legs.
+// In an acyclic graph every such path is a path to fft, followed by one from fft to dac
+// and one from dac to the target, so the count is the product of the three leg counts.
+func explainCheckpoints(t *trace.Trace, connections map[string][]string, start, target string, count checked.Int) {
+	legs := [][2]string{{start, "fft"}, {"fft", "dac"}, {"dac", target}}
+	section := t.Section("%s paths lead from %s through fft and then dac to %s", count, start, target)
+	product := checked.NewInt(1)
+	for _, leg := range legs {
+		legCount := countPaths(connections, leg[0], leg[1], map[string]checked.Int{})
+		section.Note("%s paths lead from %s to %s", legCount, leg[0], leg[1])
+		product = product.Mul(legCount)
+	}
+	section.Note("%s = the product of the three legs", product)
+
+	if reversed := countPaths(connections, "dac", "fft", map[string]checked.Int{}); reversed.Sign() == 0 {
+		t.Note("no path leads from dac to fft, so no path visits them in the wrong order")
+	} else {
+		t.Note("%s paths lead from dac to fft; paths through them in that order are not counted", reversed)
+	}
+}
+
+// commands lets the device graph of an input be explored interactively.
+func commands(run *runner.Run) map[string]runner.Command {
+	connections := readFile(run.Input)
+	_, cycleErr := createGraph(connections).TopologicalSort()
+
+	return map[string]runner.Command{
+		"paths": {
+			Args: "<from> <to>",
+			Help: "count the paths between two devices",
+			Run: func(w io.Writer, args []string) error {
+				if len(args) != 2 {
+					return fmt.Errorf("give the two devices")
+				}
+				if cycleErr != nil {
+					return fmt.Errorf("cannot count paths: %s", cycleErr)
+				}
+				fmt.Fprintf(w, "%s paths from %s to %s\n", countPaths(connections, args[0], args[1], map[string]checked.Int{}), args[0], args[1])
+				return nil
+			},
+		},
+		"outputs": {
+			Args: "<device>...",
+			Help: "list the devices a device is connected to",
+			Run: func(w io.Writer, args []string) error {
+				for _, device := range args {
+					fmt.Fprintf(w, "%s: %s\n", device, strings.Join(connections[device], " "))
+				}
+				return nil
+			},
+		},
+		"inputs": {
+			Args: "<device>...",
+			Help: "list the devices connected to a device",
+			Run: func(w io.Writer, args []string) error {
+				for _, device := range args {
+					var inputs []string
+					for from, tos := range connections {
+						if slices.Contains(tos, device) {
+							inputs = append(inputs, from)
+						}
+					}
+					slices.Sort(inputs)
+					fmt.Fprintf(w, "%s: %s\n", device, strings.Join(inputs, " "))
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// newDay describes the parts of the puzzle and the hooks of the day.
+func newDay() runner.Day {
+	return runner.Day{
+		Parts: []runner.Part{
+			runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+			runner.NewPart(solveSecond, "input3.txt", "input2.txt"),
+		},
+		Anonymize: anonymize,
+		REPL:      commands,
+		Lint:      lint,
+	}
+}
+
+func main() {
+	runner.Main(newDay())
 }
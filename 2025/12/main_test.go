@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"aoc/runner"
+	"aoc/runner/runnertest"
+	"aoc/trace"
+)
+
+func TestAnswers(t *testing.T) {
+	runnertest.Answers(t, newDay())
+}
+
+func TestDeterministic(t *testing.T) {
+	runnertest.Deterministic(t, newDay())
+}
+
+// Regions with presents of shapes the input does not draw, which lint reports, are
+// left to the search by the precheck and do not fit.
+func TestMissingShapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		count  int
+		sure   bool
+		search int
+	}{
+		{"no shapes", "3x3: 0\n", 1, true, 1},
+		{"no shapes with presents", "3x3: 0\n4x4: 0 1\n", 1, false, 1},
+		{"count past the shapes", "0:\n###\n##.\n##.\n\n6x6: 2\n4x4: 0 1\n", 1, false, 1},
+		{"zero count past the shapes", "0:\n###\n##.\n##.\n\n6x6: 2 0\n", 1, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "input.txt")
+			if err := os.WriteFile(filename, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			run := &runner.Run{Input: filename, Out: io.Discard, Trace: trace.New(0)}
+			if count, sure := solveFirstPrecheck(run); count != tt.count || sure != tt.sure {
+				t.Errorf("solveFirstPrecheck() = %d, %v, want %d, %v", count, sure, tt.count, tt.sure)
+			}
+			if count, _ := solveFirstSearch(run); count != tt.search {
+				t.Errorf("solveFirstSearch() = %d, want %d", count, tt.search)
+			}
+		})
+	}
+}
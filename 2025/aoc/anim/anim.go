@@ -0,0 +1,144 @@
+// Package anim records step-by-step snapshots of a puzzle and plays them back.
+//
+// A solver adds frames to the Recorder it is given, and the runner either plays
+// them in the terminal with ANSI colours or dumps them to text files. Recording is
+// off when the recorder is nil, so solvers check Enabled before building frames:
+//
+//	if run.Frames.Enabled() {
+//		frame := anim.Snapshot(board, "wave 1")
+//		frame.Highlight(anim.Red, removed...)
+//		run.Frames.Add(frame)
+//	}
+package anim
+
+import (
+	"fmt"
+	"strings"
+
+	"aoc/grid"
+)
+
+// Colour is the colour of highlighted cells in the terminal.
+type Colour int
+
+const (
+	Red Colour = iota + 1
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+)
+
+// ansi returns the escape sequence that switches to the colour.
+func (c Colour) ansi() string {
+	return fmt.Sprintf("\x1b[1;%dm", 30+int(c))
+}
+
+const ansiReset = "\x1b[0m"
+
+// Frame is one snapshot of a grid, with one character per cell and some cells highlighted.
+type Frame struct {
+	Caption    string
+	Rows       []string
+	Highlights map[grid.Point]Colour
+}
+
+// NewFrame creates a frame from lines of text.
+func NewFrame(caption string, rows []string) Frame {
+	return Frame{Caption: caption, Rows: rows}
+}
+
+// Snapshot creates a frame showing the current contents of a byte grid.
+func Snapshot(g *grid.Grid[byte], caption string) Frame {
+	rows := make([]string, g.Height())
+	for y := range rows {
+		rows[y] = string(g.Row(y))
+	}
+	return NewFrame(caption, rows)
+}
+
+// Highlight marks cells to be drawn in the given colour. Later calls win for cells
+// highlighted twice.
+func (f *Frame) Highlight(colour Colour, points ...grid.Point) {
+	if f.Highlights == nil {
+		f.Highlights = make(map[grid.Point]Colour, len(points))
+	}
+	for _, p := range points {
+		f.Highlights[p] = colour
+	}
+}
+
+// Render draws the frame with its caption on the first line. With colour set,
+// highlighted cells are wrapped in ANSI escape sequences; otherwise the plain
+// characters are kept so the text stays aligned.
+func (f Frame) Render(colour bool) string {
+	var sb strings.Builder
+	if f.Caption != "" {
+		sb.WriteString(f.Caption)
+		sb.WriteByte('\n')
+	}
+	for y, row := range f.Rows {
+		x := 0
+		for _, c := range row {
+			highlight, ok := f.Highlights[grid.Point{X: x, Y: y}]
+			if colour && ok {
+				sb.WriteString(highlight.ansi())
+				sb.WriteRune(c)
+				sb.WriteString(ansiReset)
+			} else {
+				sb.WriteRune(c)
+			}
+			x++
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Recorder collects the frames of one solve. A nil Recorder records nothing, so
+// solvers can use it unconditionally. It is not safe for concurrent use.
+type Recorder struct {
+	limit  int
+	frames []Frame
+}
+
+// NewRecorder creates a recorder that keeps at most limit frames, or any number if limit is 0.
+func NewRecorder(limit int) *Recorder {
+	return &Recorder{limit: limit}
+}
+
+// Enabled reports whether frames added now would be kept. It is false for a nil
+// recorder and once the limit is reached, so solvers can skip building frames.
+func (r *Recorder) Enabled() bool {
+	return r != nil && (r.limit == 0 || len(r.frames) < r.limit)
+}
+
+// Add records a frame. Frames past the limit are dropped.
+func (r *Recorder) Add(f Frame) {
+	if !r.Enabled() {
+		return
+	}
+	r.frames = append(r.frames, f)
+}
+
+// Frames returns the recorded frames in order.
+func (r *Recorder) Frames() []Frame {
+	if r == nil {
+		return nil
+	}
+	return r.frames
+}
+
+// Full reports whether the limit was reached, so later steps of the solve may be missing.
+func (r *Recorder) Full() bool {
+	return r != nil && r.limit > 0 && len(r.frames) >= r.limit
+}
+
+// Limit returns the most frames the recorder keeps, 0 for no limit.
+func (r *Recorder) Limit() int {
+	if r == nil {
+		return 0
+	}
+	return r.limit
+}
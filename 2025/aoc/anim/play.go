@@ -0,0 +1,43 @@
+package anim
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ansiClear moves the cursor home and clears the screen.
+const ansiClear = "\x1b[H\x1b[2J"
+
+// Play draws the frames one after another in a terminal, fps frames per second.
+func Play(w io.Writer, frames []Frame, fps int) error {
+	if fps <= 0 {
+		return fmt.Errorf("invalid speed %d frames per second", fps)
+	}
+	delay := time.Second / time.Duration(fps)
+
+	for i, f := range frames {
+		if _, err := fmt.Fprintf(w, "%s%sframe %d/%d\n", ansiClear, f.Render(true), i+1, len(frames)); err != nil {
+			return err
+		}
+		time.Sleep(delay)
+	}
+	return nil
+}
+
+// Dump writes each frame without colours to its own file in dir, named
+// prefix_0001.txt, prefix_0002.txt and so on. The directory is created if needed.
+func Dump(dir, prefix string, frames []Frame) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i, f := range frames {
+		name := filepath.Join(dir, fmt.Sprintf("%s_%04d.txt", prefix, i+1))
+		if err := os.WriteFile(name, []byte(f.Render(false)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package checked provides overflow-detecting integer arithmetic that falls back to math/big.
+package checked
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"sync/atomic"
+)
+
+var forceBig atomic.Bool
+
+// ForceBig makes every Int created afterwards use math/big, even when the value fits in an int.
+// Comparing answers with and without it verifies the overflow detection.
+func ForceBig(enabled bool) {
+	forceBig.Store(enabled)
+}
+
+// BigForced reports whether big-integer mode is forced.
+func BigForced() bool {
+	return forceBig.Load()
+}
+
+// Add returns a+b and whether the sum fits in an int.
+func Add(a, b int) (int, bool) {
+	sum := a + b
+	// Overflow happened if both operands have the same sign and the sum has a different one
+	return sum, (a >= 0) != (b >= 0) || (sum >= 0) == (a >= 0)
+}
+
+// Mul returns a*b and whether the product fits in an int.
+func Mul(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return math.MinInt, false
+	}
+
+	negative := (a < 0) != (b < 0)
+	hi, lo := bits.Mul64(abs(a), abs(b))
+	if hi != 0 {
+		return int(lo), false
+	}
+	if negative {
+		return -int(lo), lo <= uint64(math.MaxInt)+1
+	}
+	return int(lo), lo <= math.MaxInt
+}
+
+func abs(x int) uint64 {
+	if x < 0 {
+		return uint64(-x) // also correct for math.MinInt thanks to wrap-around
+	}
+	return uint64(x)
+}
+
+// Int is an integer that uses machine arithmetic until an operation would overflow,
+// and then transparently continues with math/big. The zero value is 0.
+//
+// Int is a value type: operations return new values and never modify their operands.
+type Int struct {
+	small int
+	big   *big.Int // set once the value no longer fits in an int, or when big mode is forced
+}
+
+// NewInt returns x as an Int.
+func NewInt(x int) Int {
+	if forceBig.Load() {
+		return Int{big: big.NewInt(int64(x))}
+	}
+	return Int{small: x}
+}
+
+// Add returns n+m.
+func (n Int) Add(m Int) Int {
+	if n.big == nil && m.big == nil && !forceBig.Load() {
+		if sum, ok := Add(n.small, m.small); ok {
+			return Int{small: sum}
+		}
+	}
+	return Int{big: new(big.Int).Add(n.Big(), m.Big())}
+}
+
+// AddInt returns n+x.
+func (n Int) AddInt(x int) Int {
+	return n.Add(Int{small: x})
+}
+
+// Mul returns n*m.
+func (n Int) Mul(m Int) Int {
+	if n.big == nil && m.big == nil && !forceBig.Load() {
+		if product, ok := Mul(n.small, m.small); ok {
+			return Int{small: product}
+		}
+	}
+	return Int{big: new(big.Int).Mul(n.Big(), m.Big())}
+}
+
+// MulInt returns n*x.
+func (n Int) MulInt(x int) Int {
+	return n.Mul(Int{small: x})
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of n.
+func (n Int) Sign() int {
+	if n.big != nil {
+		return n.big.Sign()
+	}
+	switch {
+	case n.small < 0:
+		return -1
+	case n.small > 0:
+		return 1
+	}
+	return 0
+}
+
+// Cmp compares n and m and returns -1, 0 or +1.
+func (n Int) Cmp(m Int) int {
+	if n.big == nil && m.big == nil {
+		switch {
+		case n.small < m.small:
+			return -1
+		case n.small > m.small:
+			return 1
+		}
+		return 0
+	}
+	return n.Big().Cmp(m.Big())
+}
+
+// IsBig reports whether n is backed by math/big.
+func (n Int) IsBig() bool {
+	return n.big != nil
+}
+
+// Int returns n as an int and whether it fits.
+func (n Int) Int() (int, bool) {
+	if n.big == nil {
+		return n.small, true
+	}
+	if !n.big.IsInt64() || n.big.Int64() < math.MinInt || n.big.Int64() > math.MaxInt {
+		return 0, false
+	}
+	return int(n.big.Int64()), true
+}
+
+// Big returns n as a newly allocated *big.Int.
+func (n Int) Big() *big.Int {
+	if n.big != nil {
+		return new(big.Int).Set(n.big)
+	}
+	return big.NewInt(int64(n.small))
+}
+
+// String formats n in base 10.
+func (n Int) String() string {
+	if n.big != nil {
+		return n.big.String()
+	}
+	return strconv.Itoa(n.small)
+}
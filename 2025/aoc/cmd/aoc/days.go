@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"aoc/config"
+)
+
+// findRoot returns the year directory holding the days and the shared aoc module:
+// root if given, otherwise the first directory found walking up from the working
+// directory that contains aoc/go.mod, or whose subdirectory named after the year
+// configured in aoc.toml does.
+func findRoot(root string) (string, error) {
+	if root != "" {
+		if !isRoot(root) {
+			return "", fmt.Errorf("%s does not contain the aoc module", root)
+		}
+		return filepath.Abs(root)
+	}
+
+	settings := config.Default()
+	if path, ok := config.Find("."); ok {
+		var err error
+		if settings, err = config.Load(path); err != nil {
+			return "", err
+		}
+	}
+	year := strconv.Itoa(settings.Year)
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if isRoot(dir) {
+			return dir, nil
+		}
+		if isRoot(filepath.Join(dir, year)) {
+			return filepath.Join(dir, year), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("cannot find the solutions; run aoc inside them or give -root")
+		}
+		dir = parent
+	}
+}
+
+func isRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "aoc", "go.mod"))
+	return err == nil
+}
+
+// dayDir returns the directory of a day's solution.
+func dayDir(root string, day int) (string, error) {
+	if day < 1 || day > 25 {
+		return "", fmt.Errorf("invalid day %d", day)
+	}
+	dir := filepath.Join(root, fmt.Sprintf("%02d", day))
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		return "", fmt.Errorf("day %d has no solution in %s", day, root)
+	}
+	return dir, nil
+}
+
+// dayFlags adds the flags selecting a day to a subcommand's flag set.
+func dayFlags(fs *flag.FlagSet) (day *int, root *string) {
+	day = fs.Int("day", 0, "day of the puzzle, 1 to 25")
+	root = fs.String("root", "", "directory holding the days and the aoc module; found from the working directory by default")
+	return day, root
+}
+
+// inputPath resolves an input given on the command line for a day run in its own
+// directory: a file that exists relative to the working directory is passed by its
+// absolute path, anything else is left for the day to find among its own inputs.
+func inputPath(name string) string {
+	if _, err := os.Stat(name); err != nil {
+		return name
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return name
+	}
+	return abs
+}
+
+// dayCommand returns a command running a day's solution with the runner flags in args,
+// attached to the terminal.
+func dayCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// exitCode returns the exit status of a failed day, so that aoc exits with it.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
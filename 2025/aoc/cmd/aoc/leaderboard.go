@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"aoc/config"
+	"aoc/leaderboard"
+)
+
+// runLeaderboard reports on a private leaderboard, read from its JSON export or
+// fetched from the server.
+func runLeaderboard(args []string) {
+	fs := flag.NewFlagSet("leaderboard", flag.ExitOnError)
+	id := fs.Int("id", 0, "ID of the leaderboard to fetch instead of reading a file")
+	year := fs.Int("year", 0, "event to fetch the leaderboard of; the year in aoc.toml by default")
+	session := fs.String("session", os.Getenv("AOC_SESSION"), "session cookie used to fetch the leaderboard; $AOC_SESSION by default")
+	baseURL := fs.String("url", leaderboard.DefaultBaseURL, "server to fetch the leaderboard from")
+	timeout := fs.Duration("timeout", 30*time.Second, "longest time fetching the leaderboard may take")
+	day := fs.Int("day", 0, "only show this day")
+	asJSON := fs.Bool("json", false, "write the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: aoc leaderboard [flags] file")
+		fmt.Fprintln(fs.Output(), "       aoc leaderboard -id ID [flags]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "The file is the JSON export of a private leaderboard, or - for standard input.")
+		fmt.Fprintln(fs.Output(), "Please fetch a leaderboard at most once every 15 minutes.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if (*id == 0) == (fs.NArg() == 0) || fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var board *leaderboard.Leaderboard
+	var err error
+	if *id != 0 {
+		if *year == 0 {
+			*year = configuredYear()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		fetcher := &leaderboard.Fetcher{BaseURL: *baseURL, Session: *session}
+		board, err = fetcher.Fetch(ctx, *year, *id)
+	} else {
+		board, err = leaderboard.Read(fs.Arg(0))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := leaderboard.Analyze(board)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *day != 0 {
+		if *day < 1 || *day > len(report.Days) {
+			log.Fatalf("no stars on day %d of %d", *day, report.Year)
+		}
+		report.Days = report.Days[*day-1 : *day]
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	writeLeaderboard(os.Stdout, report)
+}
+
+// configuredYear returns the year set in aoc.toml, or the default one.
+func configuredYear() int {
+	settings := config.Default()
+	if path, ok := config.Find("."); ok {
+		var err error
+		if settings, err = config.Load(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return settings.Year
+}
+
+// writeLeaderboard writes a table per day and then the standings after the last day.
+func writeLeaderboard(w io.Writer, report *leaderboard.Report) {
+	width := len("member")
+	for _, standing := range report.Standings {
+		width = max(width, utf8.RuneCountInString(standing.Name))
+	}
+
+	fmt.Fprintf(w, "%d, %d members\n", report.Year, report.Members)
+	for _, day := range report.Days {
+		fmt.Fprintf(w, "\nDay %d\n", day.Day)
+		fmt.Fprintf(w, "%4s %4s  %-*s %10s %10s %10s %6s %6s\n",
+			"rank", "", width, "member", "part 1", "part 2", "delta", "points", "score")
+		for _, r := range day.Results {
+			fmt.Fprintf(w, "%4d %4s  %-*s %10s %10s %10s %6d %6d\n",
+				r.Rank, rankChange(r.RankChange), width, r.Name,
+				duration(r.Part1), duration(r.Part2), duration(r.Delta), r.Points, r.Score)
+		}
+	}
+
+	fmt.Fprintln(w, "\nStandings")
+	fmt.Fprintf(w, "%4s  %-*s %5s %6s\n", "rank", width, "member", "stars", "score")
+	var stale []leaderboard.Standing
+	for _, s := range report.Standings {
+		fmt.Fprintf(w, "%4d  %-*s %5d %6d\n", s.Rank, width, s.Name, s.Stars, s.Score)
+		if s.Score != s.ReportedScore {
+			stale = append(stale, s)
+		}
+	}
+	if len(stale) > 0 {
+		fmt.Fprintln(w)
+		for _, s := range stale {
+			fmt.Fprintf(w, "%s: the export has a local score of %d, recomputed as %d\n", s.Name, s.ReportedScore, s.Score)
+		}
+	}
+}
+
+// rankChange formats the places a member gained, like +2 or -1, or nothing if none.
+func rankChange(change int) string {
+	if change == 0 {
+		return ""
+	}
+	if change > 0 {
+		return "+" + strconv.Itoa(change)
+	}
+	return strconv.Itoa(change)
+}
+
+func duration(d *leaderboard.Duration) string {
+	if d == nil {
+		return "-"
+	}
+	return d.String()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+// runLint checks an input against the shape a day expects.
+func runLint(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	day, root := dayFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: aoc lint --day N [flags] file")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rootDir, err := findRoot(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir, err := dayDir(rootDir, *day)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := dayCommand(dir, "-lint", "-input", inputPath(fs.Arg(0))).Run(); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
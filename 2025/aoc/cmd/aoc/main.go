@@ -0,0 +1,65 @@
+// Command aoc runs tasks that span the days of a year's solutions.
+//
+// Each day is its own Go module in a directory named after it, 01 to 25, next to
+// the shared aoc module. The aoc command finds that year directory by walking up
+// from the working directory, or takes it from -root, and runs a day by building
+// it with go run:
+//
+//	aoc repl --day 5 --input input2.txt
+//	aoc lint --day 9 input2.txt
+//	aoc status
+//	aoc leaderboard -day 3 leaderboard.json
+//	aoc serve --addr localhost:8080
+//
+// Run aoc help for the list of commands.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// command is a subcommand of aoc.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{"repl", "explore a day's parsed input interactively", runREPL},
+	{"lint", "check an input against the shape a day expects", runLint},
+	{"serve", "serve the solutions as a local HTTP/JSON API", runServe},
+	{"status", "show which parts of every year are solved", runStatus},
+	{"leaderboard", "report on a private leaderboard's stars and scores", runLeaderboard},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: aoc <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run aoc <command> -h for the flags of a command.")
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("aoc: ")
+
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage()
+		return
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			c.run(os.Args[2:])
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
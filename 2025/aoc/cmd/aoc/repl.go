@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+)
+
+// runREPL starts a day's interactive session on one input.
+func runREPL(args []string) {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	day, root := dayFlags(fs)
+	input := fs.String("input", "input1.txt", "input to explore, relative to the working directory or to the day")
+	fs.Parse(args)
+
+	rootDir, err := findRoot(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dir, err := dayDir(rootDir, *day)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := dayCommand(dir, "-repl", "-input", inputPath(*input)).Run(); err != nil {
+		os.Exit(exitCode(err))
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"aoc/runner"
+)
+
+// maxDiagnostics is how many lines of a solver's own output a response carries, counted from the end.
+const maxDiagnostics = 100
+
+// runServe serves the solutions over HTTP:
+//
+//	POST /v1/solve/{year}/{day}/{part}
+//
+// with the puzzle input as the body. The response is a JSON object with the answer,
+// the solve time and the last lines the solver printed.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	root := fs.String("root", "", "directory holding the days and the aoc module; found from the working directory by default")
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	maxBody := fs.Int64("max-body", 1<<20, "largest accepted input in bytes")
+	timeout := fs.Duration("timeout", time.Minute, "longest time a request may take, including waiting for a free solver")
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), "number of solves run at the same time")
+	fs.Parse(args)
+
+	if *concurrency < 1 {
+		log.Fatalf("invalid concurrency %d", *concurrency)
+	}
+	rootDir, err := findRoot(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	binDir, err := os.MkdirTemp("", "aoc-serve-")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(binDir)
+
+	s := &server{
+		root:    rootDir,
+		year:    filepath.Base(rootDir),
+		maxBody: *maxBody,
+		timeout: *timeout,
+		slots:   make(chan struct{}, *concurrency),
+		binDir:  binDir,
+		builds:  map[int]*build{},
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+		WriteTimeout:      *timeout + 10*time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("serving %s solutions on http://%s/v1/solve/%s/{day}/{part}", s.year, *addr, s.year)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Print(err)
+	}
+}
+
+// server runs day binaries on request bodies.
+type server struct {
+	root    string
+	year    string
+	maxBody int64
+	timeout time.Duration
+	slots   chan struct{} // holds a token for every solve in progress
+
+	binDir string
+	mu     sync.Mutex
+	builds map[int]*build
+}
+
+// routes returns the handler serving the API.
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/solve/{year}/{day}/{part}", s.solve)
+	return mux
+}
+
+// build is a day's binary, compiled on the first request for the day.
+type build struct {
+	once sync.Once
+	path string
+	err  error
+}
+
+type solveResponse struct {
+	Year         int      `json:"year"`
+	Day          int      `json:"day"`
+	Part         int      `json:"part"`
+	Answer       string   `json:"answer"`
+	Milliseconds float64  `json:"duration_ms"`
+	Diagnostics  []string `json:"diagnostics"`
+	Truncated    bool     `json:"diagnostics_truncated,omitempty"`
+}
+
+type errorResponse struct {
+	Error       string   `json:"error"`
+	Diagnostics []string `json:"diagnostics,omitempty"`
+}
+
+func (s *server) solve(w http.ResponseWriter, r *http.Request) {
+	year, errYear := strconv.Atoi(r.PathValue("year"))
+	day, errDay := strconv.Atoi(r.PathValue("day"))
+	part, errPart := strconv.Atoi(r.PathValue("part"))
+	if errYear != nil || errDay != nil || errPart != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "year, day and part must be numbers"})
+		return
+	}
+	if r.PathValue("year") != s.year {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("no solutions for %d, only for %s", year, s.year)})
+		return
+	}
+	dir, err := dayDir(s.root, day)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+	if part < 1 {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: fmt.Sprintf("invalid part %d", part)})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: fmt.Sprintf("input larger than %d bytes", s.maxBody)})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "failed to read input: " + err.Error()})
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "empty input"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "too many solves in progress, try again later"})
+		return
+	}
+
+	binary, err := s.binary(day, dir)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: fmt.Sprintf("failed to build day %d", day), Diagnostics: lines(err.Error())})
+		return
+	}
+
+	inputFile, err := os.CreateTemp("", "aoc-input-*.txt")
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	defer os.Remove(inputFile.Name())
+	_, err = inputFile.Write(body)
+	if closeErr := inputFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, "-json", "-part", strconv.Itoa(part), "-input", inputFile.Name())
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+
+	diagnostics, truncated := lastLines(stderr.String(), maxDiagnostics)
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		writeJSON(w, http.StatusGatewayTimeout, errorResponse{Error: fmt.Sprintf("no answer within %s", s.timeout), Diagnostics: diagnostics})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "the solver failed: " + err.Error(), Diagnostics: diagnostics})
+		return
+	}
+
+	var answer runner.Answer
+	if err := json.Unmarshal(stdout.Bytes(), &answer); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "the solver gave no answer", Diagnostics: diagnostics})
+		return
+	}
+	writeJSON(w, http.StatusOK, solveResponse{
+		Year:         year,
+		Day:          day,
+		Part:         part,
+		Answer:       answer.Answer,
+		Milliseconds: answer.Milliseconds,
+		Diagnostics:  diagnostics,
+		Truncated:    truncated,
+	})
+}
+
+// binary returns the path of a day's compiled solution, building it the first time.
+// A failed build is forgotten once the requests waiting for it have its error, so that
+// the next request builds the day again, say after its code is fixed.
+func (s *server) binary(day int, dir string) (string, error) {
+	s.mu.Lock()
+	b, ok := s.builds[day]
+	if !ok {
+		b = &build{path: filepath.Join(s.binDir, fmt.Sprintf("%02d", day))}
+		s.builds[day] = b
+	}
+	s.mu.Unlock()
+
+	b.once.Do(func() {
+		cmd := exec.Command("go", "build", "-o", b.path, ".")
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			b.err = fmt.Errorf("%s\n%s", err, output)
+		}
+	})
+	if b.err != nil {
+		s.mu.Lock()
+		if s.builds[day] == b {
+			delete(s.builds, day)
+		}
+		s.mu.Unlock()
+	}
+	return b.path, b.err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func lines(s string) []string {
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
+
+// lastLines returns the last n lines of s and whether earlier ones were dropped.
+func lastLines(s string, n int) ([]string, bool) {
+	if strings.TrimSpace(s) == "" {
+		return []string{}, false
+	}
+	all := lines(s)
+	if len(all) <= n {
+		return all, false
+	}
+	return all[len(all)-n:], true
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDay is a solution answering with the number of words of its input, printing a
+// line of its own output on the way. It sleeps on the input "sleep".
+const fakeDay = `package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	input := flag.String("input", "", "")
+	flag.Int("part", 0, "")
+	flag.Bool("json", false, "")
+	flag.Parse()
+	data, err := os.ReadFile(*input)
+	if err != nil {
+		panic(err)
+	}
+	if strings.TrimSpace(string(data)) == "sleep" {
+		time.Sleep(time.Minute)
+	}
+	fmt.Fprintln(os.Stderr, "solving")
+	fmt.Printf("{\"part\":1,\"answer\":\"%d\",\"duration_ms\":1}\n", len(strings.Fields(string(data))))
+}
+`
+
+// writeDay writes a day of its own module into root.
+func writeDay(t *testing.T, root, day, source string) {
+	t.Helper()
+	dir := filepath.Join(root, day)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module day\n\ngo 1.25\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("serving builds the days with the go command")
+	}
+	root := filepath.Join(t.TempDir(), "2025")
+	writeDay(t, root, "01", fakeDay)
+	writeDay(t, root, "02", "package main\n\nfunc main() { undefined() }\n")
+
+	s := &server{
+		root:    root,
+		year:    "2025",
+		maxBody: 64,
+		timeout: time.Minute,
+		slots:   make(chan struct{}, 2),
+		binDir:  t.TempDir(),
+		builds:  map[int]*build{},
+	}
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	// The solves run in order: day 02 is fixed between its two requests, and the
+	// request that times out gets a shorter timeout
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		before  func()
+		status  int
+		answer  string
+		message string // part of the error for failed requests
+	}{
+		{name: "input too large", path: "2025/1/1", body: strings.Repeat("1 ", 40), status: http.StatusRequestEntityTooLarge, message: "larger than 64 bytes"},
+		{name: "empty input", path: "2025/1/1", body: " \n", status: http.StatusBadRequest, message: "empty input"},
+		{name: "day not a number", path: "2025/one/1", body: "1", status: http.StatusBadRequest, message: "must be numbers"},
+		{name: "other year", path: "2024/1/1", body: "1", status: http.StatusNotFound, message: "only for 2025"},
+		{name: "day without a solution", path: "2025/3/1", body: "1", status: http.StatusNotFound, message: "no solution"},
+		{name: "invalid part", path: "2025/1/0", body: "1", status: http.StatusNotFound, message: "invalid part"},
+		{name: "answer", path: "2025/1/1", body: "1 2 3\n", status: http.StatusOK, answer: "3"},
+		{
+			name:    "timeout",
+			path:    "2025/1/2",
+			body:    "sleep\n",
+			before:  func() { s.timeout = 500 * time.Millisecond },
+			status:  http.StatusGatewayTimeout,
+			message: "no answer within",
+		},
+		{
+			name:    "build failure",
+			path:    "2025/2/1",
+			body:    "1",
+			before:  func() { s.timeout = time.Minute },
+			status:  http.StatusInternalServerError,
+			message: "failed to build day 2",
+		},
+		{
+			name:   "build retried once fixed",
+			path:   "2025/2/1",
+			body:   "4 5\n",
+			before: func() { writeDay(t, root, "02", fakeDay) },
+			status: http.StatusOK,
+			answer: "2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			resp, err := http.Post(ts.URL+"/v1/solve/"+tt.path, "text/plain", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			if tt.status == http.StatusOK {
+				var got solveResponse
+				if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Answer != tt.answer || got.Year != 2025 || len(got.Diagnostics) != 1 || got.Diagnostics[0] != "solving" {
+					t.Errorf("response = %+v, want answer %s and the line the solver printed", got, tt.answer)
+				}
+				return
+			}
+			var got errorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got.Error, tt.message) {
+				t.Errorf("error = %q, want it to contain %q", got.Error, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"aoc/registry"
+)
+
+// runStatus shows which parts of every year are solved as a star chart, and what is
+// missing from each day.
+func runStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	repo := fs.String("repo", "", "directory holding the years; the parent of the year found from the working directory by default")
+	year := fs.Int("year", 0, "only show this year")
+	fs.Parse(args)
+
+	if *repo == "" {
+		root, err := findRoot("")
+		if err != nil {
+			log.Fatal(err)
+		}
+		*repo = filepath.Dir(root)
+	}
+	years, err := registry.Scan(*repo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *year != 0 {
+		years = slices.DeleteFunc(years, func(y *registry.Year) bool { return y.Number != *year })
+		if len(years) == 0 {
+			log.Fatalf("no solutions for %d in %s", *year, *repo)
+		}
+	}
+
+	writeStarChart(os.Stdout, years)
+
+	var problems []string
+	for _, y := range years {
+		for _, day := range y.Days {
+			for _, problem := range day.Problems() {
+				problems = append(problems, fmt.Sprintf("%d day %d: %s", day.Year, day.Number, problem))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Println()
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+	}
+}
+
+// writeStarChart writes a row per year with ** for each day with both parts solved,
+// * for a day with one part, and . for a day not solved.
+func writeStarChart(w io.Writer, years []*registry.Year) {
+	fmt.Fprint(w, "    ")
+	for day := 1; day <= registry.Days; day++ {
+		fmt.Fprintf(w, " %2d", day)
+	}
+	fmt.Fprintln(w, "  stars  languages")
+
+	for _, y := range years {
+		cells := make([]string, registry.Days)
+		for i := range cells {
+			cells[i] = "."
+		}
+		stars := 0
+		var languages []string
+		for _, day := range y.Days {
+			if day.Parts > 0 {
+				cells[day.Number-1] = strings.Repeat("*", day.Parts)
+			}
+			stars += day.Parts
+			if day.Language != "" && !slices.Contains(languages, day.Language) {
+				languages = append(languages, day.Language)
+			}
+		}
+
+		fmt.Fprintf(w, "%d", y.Number)
+		for _, cell := range cells {
+			fmt.Fprintf(w, " %2s", cell)
+		}
+		slices.Sort(languages)
+		fmt.Fprintf(w, "  %5d  %s\n", stars, strings.Join(languages, ", "))
+	}
+}
@@ -0,0 +1,135 @@
+// Package config loads aoc.toml, the settings shared by the solutions and the aoc command.
+//
+// A configuration file looks like this; every setting is optional:
+//
+//	year = 2025           # year the aoc command works on
+//	input_dir = "."       # where a day's default inputs live, relative to the day
+//	output = "text"       # how answers are printed: "text", "quiet" or "json"
+//	alloc_budget = "64M"  # most a solve may allocate before the run fails
+//
+//	[day.08]              # named parameters read by a day's solvers
+//	connections = 1000
+//
+// The file is found by walking up from the working directory, see Find.
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileName is the name of the configuration file.
+const FileName = "aoc.toml"
+
+// Config holds the settings from a configuration file.
+type Config struct {
+	Path string // file the settings were loaded from, or "" for the defaults
+
+	Year int
+	// InputDir is the directory of a day's default inputs relative to the day's directory.
+	// {day} stands for the two-digit day.
+	InputDir string
+	Output   string
+	// AllocBudget is the most bytes a solve may allocate, with an optional K, M or G
+	// suffix, or "" for no budget.
+	AllocBudget string
+
+	// Days holds the named parameters of each day, keyed by the day's number.
+	Days map[int]Table
+}
+
+// Outputs lists the valid values of Output.
+var Outputs = []string{"text", "quiet", "json"}
+
+// Default returns the settings used without a configuration file.
+func Default() *Config {
+	return &Config{Year: 2025, InputDir: ".", Output: "text", Days: map[int]Table{}}
+}
+
+// Find looks for FileName in dir and its parents and returns the path of the first one.
+func Find(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Load reads a configuration file. Settings it leaves out keep their defaults.
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tables, err := Parse(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := Default()
+	c.Path = path
+	// Settings are checked in sorted order so that the first error is always the same one
+	for _, key := range slices.Sorted(maps.Keys(tables[""])) {
+		value := tables[""][key]
+		var ok bool
+		switch key {
+		case "year":
+			var year int64
+			year, ok = value.(int64)
+			c.Year = int(year)
+		case "input_dir":
+			c.InputDir, ok = value.(string)
+		case "alloc_budget":
+			c.AllocBudget, ok = value.(string)
+		case "output":
+			c.Output, ok = value.(string)
+			if ok && !slices.Contains(Outputs, c.Output) {
+				return nil, fmt.Errorf("%s: output must be one of %s", path, strings.Join(Outputs, ", "))
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown setting %s", path, key)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: %s has the wrong type", path, key)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(tables)) {
+		table := tables[name]
+		if name == "" {
+			continue
+		}
+		number, ok := strings.CutPrefix(name, "day.")
+		day, err := strconv.Atoi(number)
+		if !ok || err != nil || day < 1 || day > 25 {
+			return nil, fmt.Errorf("%s: unknown table %s; day tables are named like [day.08]", path, name)
+		}
+		if _, ok := c.Days[day]; ok {
+			return nil, fmt.Errorf("%s: day %d configured twice", path, day)
+		}
+		c.Days[day] = table
+	}
+	return c, nil
+}
+
+// DayInputDir returns the directory of a day's default inputs relative to the day's directory.
+func (c *Config) DayInputDir(day int) string {
+	return strings.ReplaceAll(c.InputDir, "{day}", fmt.Sprintf("%02d", day))
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table holds the keys of one TOML table. Values are of type string, int64, float64 or bool.
+type Table map[string]any
+
+// Parse reads a TOML document and returns its tables by name, with the top-level keys
+// in the table named "". Only the part of TOML that configuration files need is
+// supported: [table] headers with dotted names, bare keys, and single-line values
+// that are strings, integers, floats or booleans. Errors give the line of the input
+// called name.
+func Parse(r io.Reader, name string) (map[string]Table, error) {
+	tables := map[string]Table{"": {}}
+	current := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fail := func(format string, args ...any) error {
+			return fmt.Errorf("%s:%d: %s", name, lineNumber, fmt.Sprintf(format, args...))
+		}
+
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fail("invalid table header %s", line)
+			}
+			parts := strings.Split(line[1:len(line)-1], ".")
+			for i, part := range parts {
+				parts[i] = strings.TrimSpace(part)
+				if !isBareKey(parts[i]) {
+					return nil, fail("invalid table name %s", line)
+				}
+			}
+			current = strings.Join(parts, ".")
+			if _, ok := tables[current]; ok {
+				return nil, fail("table %s defined twice", current)
+			}
+			tables[current] = Table{}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isBareKey(key) {
+			return nil, fail("expected key = value, got %s", line)
+		}
+		value, err := ParseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fail("%s: %s", key, err)
+		}
+		if _, ok := tables[current][key]; ok {
+			return nil, fail("key %s set twice", key)
+		}
+		tables[current][key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// ParseValue parses a single TOML value: a basic "string" or literal 'string', a
+// decimal integer such as 1_000, a decimal float such as 2.5e-3 or a boolean. The
+// special floats inf and nan are not supported.
+func ParseValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s[0] == '"':
+		value, err := unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s: %s", s, err)
+		}
+		return value, nil
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' || strings.Contains(s[1:len(s)-1], "'") {
+			return nil, fmt.Errorf("invalid string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case s[0] == '[' || s[0] == '{':
+		return nil, fmt.Errorf("arrays and inline tables are not supported")
+	}
+
+	if !number.MatchString(s) {
+		return nil, fmt.Errorf("invalid value %s; quote strings", s)
+	}
+	digits := strings.ReplaceAll(s, "_", "")
+	if !strings.ContainsAny(digits, ".eE") {
+		value, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", s)
+		}
+		return value, nil
+	}
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return nil, fmt.Errorf("float %s is out of range", s)
+	}
+	return value, nil
+}
+
+// number matches the decimal integers and floats of TOML: no leading zeros, and
+// underscores only between digits.
+var number = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+
+// unquote returns the text of a TOML basic string, replacing its escape sequences,
+// which are fewer than Go's.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("missing closing quote")
+	}
+	body := s[1 : len(s)-1]
+
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '"':
+			return "", fmt.Errorf("unescaped quote")
+		case c < 0x20 && c != '\t' || c == 0x7f:
+			return "", fmt.Errorf("control character %q", c)
+		case c != '\\':
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(body) {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		switch e := body[i]; e {
+		case 'b':
+			sb.WriteByte('\b')
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'r':
+			sb.WriteByte('\r')
+		case '"', '\\':
+			sb.WriteByte(e)
+		case 'u', 'U':
+			size := 4
+			if e == 'U' {
+				size = 8
+			}
+			hex := body[i+1 : min(i+1+size, len(body))]
+			code, err := strconv.ParseUint(hex, 16, 32)
+			if len(hex) != size || err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape \\%c%s", e, hex)
+			}
+			sb.WriteRune(rune(code))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", e)
+		}
+	}
+	return sb.String(), nil
+}
+
+// stripComment removes a # comment that is not inside a string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0 && c == '\\' && quote == '"':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
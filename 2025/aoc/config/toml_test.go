@@ -0,0 +1,135 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{`true`, true},
+		{`false`, false},
+		{`0`, int64(0)},
+		{`1_000`, int64(1000)},
+		{`-17`, int64(-17)},
+		{`+5`, int64(5)},
+		{`2.5`, 2.5},
+		{`-0.25e2`, -25.0},
+		{`1e3`, 1000.0},
+		{`"text"`, "text"},
+		{`""`, ""},
+		{`"tab\there"`, "tab\there"},
+		{`"quote \" and backslash \\"`, `quote " and backslash \`},
+		{`"\u00e9\U0001F384"`, "é🎄"},
+		{`'C:\path'`, `C:\path`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseValue(tt.in)
+			if err != nil {
+				t.Fatalf("ParseValue(%s) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseValue(%s) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValueInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ``},
+		{"bare word", `yes`},
+		{"array", `[1, 2]`},
+		{"inline table", `{a = 1}`},
+		{"unterminated string", `"open`},
+		{"unescaped quote", `"a"b"`},
+		{"Go hex escape", `"\x41"`},
+		{"Go octal escape", `"\101"`},
+		{"Go bell escape", `"\a"`},
+		{"Go single quote escape", `"\'"`},
+		{"short unicode escape", `"\u00e"`},
+		{"surrogate escape", `"\ud800"`},
+		{"raw newline", "\"a\nb\""},
+		{"unterminated literal string", `'open`},
+		{"inf", `inf`},
+		{"Go infinity", `+Inf`},
+		{"nan", `nan`},
+		{"Go hex float", `0x1p-2`},
+		{"hex integer", `0x10`},
+		{"leading zero", `007`},
+		{"leading underscore", `_1`},
+		{"trailing underscore", `1_`},
+		{"double underscore", `1__0`},
+		{"bare dot", `.5`},
+		{"out of range", `9_223_372_036_854_775_808`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseValue(tt.in); err == nil {
+				t.Errorf("ParseValue(%s) = %#v, want an error", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	const doc = `# Settings
+year = 2025 # trailing comment
+output = "quiet # not a comment"
+
+[day.08]
+connections = 1_000
+
+[ day . 11 ]
+start = 'you'
+`
+	got, err := Parse(strings.NewReader(doc), "aoc.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Table{
+		"":       {"year": int64(2025), "output": "quiet # not a comment"},
+		"day.08": {"connections": int64(1000)},
+		"day.11": {"start": "you"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string // prefix of the error, with the line
+	}{
+		{"key without value", "year = 2025\noutput\n", "aoc.toml:2: "},
+		{"bad value", "\n\n[day.01]\ndial_max = 0x63\n", "aoc.toml:4: dial_max: "},
+		{"Go escape", "output = \"\\x71uiet\"\n", "aoc.toml:1: output: "},
+		{"key set twice", "[day.01]\ndial_max = 99\ndial_max = 98\n", "aoc.toml:3: key dial_max set twice"},
+		{"table defined twice", "[day.01]\n[day.02]\n[day.01]\n", "aoc.toml:3: table day.01 defined twice"},
+		{"array of tables", "[[day]]\n", "aoc.toml:1: invalid table header"},
+		{"unclosed header", "# settings\n[day.01\n", "aoc.toml:2: invalid table header"},
+		{"empty table name", "[day..01]\n", "aoc.toml:1: invalid table name"},
+		{"quoted key", "\"year\" = 2025\n", "aoc.toml:1: expected key = value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.doc), "aoc.toml")
+			if err == nil {
+				t.Fatalf("Parse() succeeded, want an error starting %q", tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %q, want it to start %q", err, tt.want)
+			}
+		})
+	}
+}
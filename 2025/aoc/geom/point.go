@@ -0,0 +1,147 @@
+// Package geom provides integer-exact computational geometry for 2D and 3D points,
+// polygons and axis-aligned rectangles.
+//
+// All coordinates are ints and no operation converts through floating point, so
+// results are exact as long as products of coordinate differences fit in an int.
+package geom
+
+import (
+	"fmt"
+	"math"
+)
+
+// Point2 is a point or vector in the plane.
+type Point2 struct {
+	X, Y int
+}
+
+// Add returns p + q.
+func (p Point2) Add(q Point2) Point2 {
+	return Point2{p.X + q.X, p.Y + q.Y}
+}
+
+// Sub returns p - q.
+func (p Point2) Sub(q Point2) Point2 {
+	return Point2{p.X - q.X, p.Y - q.Y}
+}
+
+// Scale returns p multiplied by k.
+func (p Point2) Scale(k int) Point2 {
+	return Point2{p.X * k, p.Y * k}
+}
+
+// Dot returns the dot product of p and q.
+func (p Point2) Dot(q Point2) int {
+	return p.X*q.X + p.Y*q.Y
+}
+
+// Cross returns the z component of the cross product of p and q. It is positive when q
+// is counter-clockwise from p, negative when clockwise and zero when they are collinear.
+func (p Point2) Cross(q Point2) int {
+	return p.X*q.Y - p.Y*q.X
+}
+
+// Manhattan returns the taxicab distance between p and q.
+func (p Point2) Manhattan(q Point2) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y)
+}
+
+// Chebyshev returns the chessboard distance between p and q.
+func (p Point2) Chebyshev(q Point2) int {
+	return max(abs(p.X-q.X), abs(p.Y-q.Y))
+}
+
+// SquaredDistance returns the squared Euclidean distance between p and q.
+// It orders points exactly like the Euclidean distance without taking a square root.
+func (p Point2) SquaredDistance(q Point2) int {
+	d := p.Sub(q)
+	return d.Dot(d)
+}
+
+// Distance returns the Euclidean distance between p and q.
+func (p Point2) Distance(q Point2) float64 {
+	return math.Sqrt(float64(p.SquaredDistance(q)))
+}
+
+// String formats the point as "X,Y", the format most puzzle inputs use.
+func (p Point2) String() string {
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+// Point3 is a point or vector in space.
+type Point3 struct {
+	X, Y, Z int
+}
+
+// Add returns p + q.
+func (p Point3) Add(q Point3) Point3 {
+	return Point3{p.X + q.X, p.Y + q.Y, p.Z + q.Z}
+}
+
+// Sub returns p - q.
+func (p Point3) Sub(q Point3) Point3 {
+	return Point3{p.X - q.X, p.Y - q.Y, p.Z - q.Z}
+}
+
+// Scale returns p multiplied by k.
+func (p Point3) Scale(k int) Point3 {
+	return Point3{p.X * k, p.Y * k, p.Z * k}
+}
+
+// Dot returns the dot product of p and q.
+func (p Point3) Dot(q Point3) int {
+	return p.X*q.X + p.Y*q.Y + p.Z*q.Z
+}
+
+// Cross returns the cross product of p and q.
+func (p Point3) Cross(q Point3) Point3 {
+	return Point3{
+		p.Y*q.Z - p.Z*q.Y,
+		p.Z*q.X - p.X*q.Z,
+		p.X*q.Y - p.Y*q.X,
+	}
+}
+
+// Manhattan returns the taxicab distance between p and q.
+func (p Point3) Manhattan(q Point3) int {
+	return abs(p.X-q.X) + abs(p.Y-q.Y) + abs(p.Z-q.Z)
+}
+
+// Chebyshev returns the chessboard distance between p and q.
+func (p Point3) Chebyshev(q Point3) int {
+	return max(abs(p.X-q.X), abs(p.Y-q.Y), abs(p.Z-q.Z))
+}
+
+// SquaredDistance returns the squared Euclidean distance between p and q.
+// It orders points exactly like the Euclidean distance without taking a square root.
+func (p Point3) SquaredDistance(q Point3) int {
+	d := p.Sub(q)
+	return d.Dot(d)
+}
+
+// Distance returns the Euclidean distance between p and q.
+func (p Point3) Distance(q Point3) float64 {
+	return math.Sqrt(float64(p.SquaredDistance(q)))
+}
+
+// String formats the point as "X,Y,Z", the format most puzzle inputs use.
+func (p Point3) String() string {
+	return fmt.Sprintf("%d,%d,%d", p.X, p.Y, p.Z)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
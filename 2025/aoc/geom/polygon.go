@@ -0,0 +1,229 @@
+package geom
+
+import (
+	"iter"
+	"slices"
+)
+
+// Orientation is the turning direction of three points, or the winding of a polygon,
+// in a coordinate system where Y grows upwards. With Y growing downwards, as on
+// puzzle grids, clockwise and counter-clockwise swap.
+type Orientation int
+
+const (
+	Clockwise        Orientation = -1
+	Collinear        Orientation = 0
+	CounterClockwise Orientation = 1
+)
+
+// Orient returns the orientation of the turn a -> b -> c.
+func Orient(a, b, c Point2) Orientation {
+	return Orientation(sign(b.Sub(a).Cross(c.Sub(a))))
+}
+
+// Segment is the closed line segment between A and B.
+type Segment struct {
+	A, B Point2
+}
+
+// Contains reports whether p lies on the segment, including its endpoints.
+func (s Segment) Contains(p Point2) bool {
+	return Orient(s.A, s.B, p) == Collinear &&
+		min(s.A.X, s.B.X) <= p.X && p.X <= max(s.A.X, s.B.X) &&
+		min(s.A.Y, s.B.Y) <= p.Y && p.Y <= max(s.A.Y, s.B.Y)
+}
+
+// Intersects reports whether the segments share at least one point.
+func (s Segment) Intersects(t Segment) bool {
+	d1, d2 := Orient(s.A, s.B, t.A), Orient(s.A, s.B, t.B)
+	d3, d4 := Orient(t.A, t.B, s.A), Orient(t.A, t.B, s.B)
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+	return s.Contains(t.A) || s.Contains(t.B) || t.Contains(s.A) || t.Contains(s.B)
+}
+
+// Location describes where a point lies relative to a polygon.
+type Location int
+
+const (
+	Outside Location = iota
+	Boundary
+	Inside
+)
+
+// Polygon is a simple polygon given by its vertices in order. The last vertex
+// connects back to the first.
+type Polygon []Point2
+
+// Edges iterates over the edges of the polygon, including the closing edge.
+func (pg Polygon) Edges() iter.Seq[Segment] {
+	return func(yield func(Segment) bool) {
+		for i, a := range pg {
+			if !yield(Segment{a, pg[(i+1)%len(pg)]}) {
+				return
+			}
+		}
+	}
+}
+
+// SignedArea2 returns twice the signed area of the polygon, computed with the shoelace
+// formula. It is positive for counter-clockwise polygons and negative for clockwise ones.
+func (pg Polygon) SignedArea2() int {
+	sum := 0
+	for e := range pg.Edges() {
+		sum += e.A.Cross(e.B)
+	}
+	return sum
+}
+
+// Area2 returns twice the area of the polygon. Doubling keeps the result an exact integer.
+func (pg Polygon) Area2() int {
+	return abs(pg.SignedArea2())
+}
+
+// Orientation returns the winding direction of the polygon, or Collinear if it has no area.
+func (pg Polygon) Orientation() Orientation {
+	return Orientation(sign(pg.SignedArea2()))
+}
+
+// BoundaryPoints returns the number of lattice points on the edges of the polygon.
+func (pg Polygon) BoundaryPoints() int {
+	count := 0
+	for e := range pg.Edges() {
+		count += gcd(abs(e.B.X-e.A.X), abs(e.B.Y-e.A.Y))
+	}
+	return count
+}
+
+// InteriorPoints returns the number of lattice points strictly inside the polygon,
+// using Pick's theorem.
+func (pg Polygon) InteriorPoints() int {
+	return (pg.Area2()-pg.BoundaryPoints())/2 + 1
+}
+
+// IsRectilinear reports whether every edge of the polygon is horizontal or vertical.
+func (pg Polygon) IsRectilinear() bool {
+	for e := range pg.Edges() {
+		if e.A.X != e.B.X && e.A.Y != e.B.Y {
+			return false
+		}
+	}
+	return true
+}
+
+// Locate reports whether p lies inside the polygon, on its boundary or outside it.
+func (pg Polygon) Locate(p Point2) Location {
+	return pg.locate(p, 1)
+}
+
+// Contains reports whether p lies inside the polygon or on its boundary.
+func (pg Polygon) Contains(p Point2) bool {
+	return pg.Locate(p) != Outside
+}
+
+// locate classifies p against the polygon with every vertex multiplied by scale.
+// Scaling lets callers test points with half-integer coordinates exactly.
+func (pg Polygon) locate(p Point2, scale int) Location {
+	inside := false
+	for e := range pg.Edges() {
+		a, b := e.A.Scale(scale), e.B.Scale(scale)
+		if (Segment{a, b}).Contains(p) {
+			return Boundary
+		}
+
+		// Cast a ray towards +X and count the edges it crosses. The half-open test on Y
+		// counts a vertex on the ray once, and only edges that straddle the ray qualify.
+		if (a.Y > p.Y) != (b.Y > p.Y) {
+			// p is left of the crossing when (p.X - a.X) < (b.X - a.X) * (p.Y - a.Y) / dy,
+			// multiplied out by dy (whose sign flips the comparison) to stay exact
+			dy := b.Y - a.Y
+			if sign(dy)*((b.X-a.X)*(p.Y-a.Y)-(p.X-a.X)*dy) > 0 {
+				inside = !inside
+			}
+		}
+	}
+	if inside {
+		return Inside
+	}
+	return Outside
+}
+
+// ContainsRect reports whether the closed rectangle r lies entirely inside the polygon
+// or on its boundary. The polygon must be rectilinear; ContainsRect panics otherwise.
+func (pg Polygon) ContainsRect(r Rect) bool {
+	if !pg.IsRectilinear() {
+		panic("geom: ContainsRect requires a rectilinear polygon")
+	}
+	for _, corner := range r.Corners() {
+		if !pg.Contains(corner) {
+			return false
+		}
+	}
+
+	if r.Min.X == r.Max.X || r.Min.Y == r.Max.Y {
+		return pg.containsSegment(Segment{r.Min, r.Max})
+	}
+
+	// No edge may pass through the open interior of the rectangle
+	for e := range pg.Edges() {
+		if e.A.Y == e.B.Y {
+			if r.Min.Y < e.A.Y && e.A.Y < r.Max.Y &&
+				max(min(e.A.X, e.B.X), r.Min.X) < min(max(e.A.X, e.B.X), r.Max.X) {
+				return false
+			}
+		} else if r.Min.X < e.A.X && e.A.X < r.Max.X &&
+			max(min(e.A.Y, e.B.Y), r.Min.Y) < min(max(e.A.Y, e.B.Y), r.Max.Y) {
+			return false
+		}
+	}
+
+	// The interior is now either entirely inside or entirely outside, and the centre decides.
+	// Doubling the coordinates keeps the centre on the lattice.
+	centre := r.Min.Add(r.Max)
+	return pg.locate(centre, 2) == Inside
+}
+
+// containsSegment reports whether a horizontal or vertical segment whose endpoints are
+// in the polygon lies entirely within it. The segment is split at every vertex
+// coordinate along it, and each piece is decided by its midpoint.
+func (pg Polygon) containsSegment(s Segment) bool {
+	horizontal := s.A.Y == s.B.Y
+	along := func(p Point2) int {
+		if horizontal {
+			return p.X
+		}
+		return p.Y
+	}
+	lo, hi := min(along(s.A), along(s.B)), max(along(s.A), along(s.B))
+
+	cuts := []int{lo, hi}
+	for _, v := range pg {
+		if c := along(v); lo < c && c < hi {
+			cuts = append(cuts, c)
+		}
+	}
+	slices.Sort(cuts)
+	cuts = slices.Compact(cuts)
+
+	for i := 1; i < len(cuts); i++ {
+		mid := cuts[i-1] + cuts[i]
+		var p Point2
+		if horizontal {
+			p = Point2{mid, 2 * s.A.Y}
+		} else {
+			p = Point2{2 * s.A.X, mid}
+		}
+		if pg.locate(p, 2) == Outside {
+			return false
+		}
+	}
+	return true
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
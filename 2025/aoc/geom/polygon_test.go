@@ -0,0 +1,78 @@
+package geom
+
+import "testing"
+
+// u is a U-shaped polygon, 6 wide and 6 high, whose notch spans 2 < x < 4 from y = 2 up.
+var u = Polygon{{0, 0}, {6, 0}, {6, 6}, {4, 6}, {4, 2}, {2, 2}, {2, 6}, {0, 6}}
+
+// rect returns the rectangle from (x1, y1) to (x2, y2).
+func rect(x1, y1, x2, y2 int) Rect {
+	return Rect{Point2{x1, y1}, Point2{x2, y2}}
+}
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Point2
+		want Location
+	}{
+		{"inside the base", Point2{3, 1}, Inside},
+		{"inside a leg", Point2{5, 5}, Inside},
+		{"in the notch", Point2{3, 4}, Outside},
+		{"at the mouth of the notch", Point2{3, 6}, Outside},
+		{"on the floor of the notch", Point2{3, 2}, Boundary},
+		{"on a side of the notch", Point2{2, 4}, Boundary},
+		{"on an outer edge", Point2{0, 3}, Boundary},
+		{"on a vertex", Point2{4, 6}, Boundary},
+		{"ray along the floor of the notch", Point2{1, 2}, Inside},
+		{"ray along the top edges", Point2{-1, 6}, Outside},
+		{"ray through two legs", Point2{-1, 4}, Outside},
+		{"right of the polygon", Point2{7, 3}, Outside},
+		{"below the polygon", Point2{3, -1}, Outside},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.Locate(tt.p); got != tt.want {
+				t.Errorf("Locate(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainsRect(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Rect
+		want bool
+	}{
+		{"base", rect(0, 0, 6, 2), true},
+		{"left leg", rect(0, 0, 2, 6), true},
+		{"inner square", rect(4, 3, 5, 5), true},
+		{"whole polygon", rect(0, 0, 6, 6), false},
+		{"into the notch", rect(0, 0, 6, 3), false},
+		{"corners inside, notch between", rect(1, 1, 5, 5), false},
+		{"the notch itself", rect(2, 2, 4, 6), false},
+		{"outside", rect(7, 7, 8, 8), false},
+		{"line along the floor of the notch", rect(0, 2, 6, 2), true},
+		{"line across the notch", rect(1, 4, 5, 4), false},
+		{"line up a leg", rect(5, 0, 5, 6), true},
+		{"line up the notch", rect(3, 0, 3, 4), false},
+		{"point inside", rect(1, 1, 1, 1), true},
+		{"point on the boundary", rect(2, 6, 2, 6), true},
+		{"point in the notch", rect(3, 4, 3, 4), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.ContainsRect(tt.r); got != tt.want {
+				t.Errorf("ContainsRect(%v) = %v, want %v", tt.r, got, tt.want)
+			}
+		})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("ContainsRect() on a triangle did not panic")
+		}
+	}()
+	Polygon{{0, 0}, {4, 0}, {0, 4}}.ContainsRect(rect(0, 0, 1, 1))
+}
@@ -0,0 +1,58 @@
+package geom
+
+// Rect is the closed axis-aligned rectangle spanning Min to Max inclusive,
+// with Min.X <= Max.X and Min.Y <= Max.Y.
+type Rect struct {
+	Min, Max Point2
+}
+
+// RectFromCorners returns the rectangle with a and b as opposite corners, in any order.
+func RectFromCorners(a, b Point2) Rect {
+	return Rect{
+		Min: Point2{min(a.X, b.X), min(a.Y, b.Y)},
+		Max: Point2{max(a.X, b.X), max(a.Y, b.Y)},
+	}
+}
+
+// Width returns the number of columns covered by the rectangle, counting both edges.
+func (r Rect) Width() int {
+	return r.Max.X - r.Min.X + 1
+}
+
+// Height returns the number of rows covered by the rectangle, counting both edges.
+func (r Rect) Height() int {
+	return r.Max.Y - r.Min.Y + 1
+}
+
+// Area returns the number of lattice points in the rectangle, which is the number of
+// tiles it covers when every point is a tile.
+func (r Rect) Area() int {
+	return r.Width() * r.Height()
+}
+
+// Corners returns the four corners, starting at Min and going around the rectangle.
+func (r Rect) Corners() [4]Point2 {
+	return [4]Point2{r.Min, {r.Max.X, r.Min.Y}, r.Max, {r.Min.X, r.Max.Y}}
+}
+
+// Contains reports whether p lies in the rectangle or on its edges.
+func (r Rect) Contains(p Point2) bool {
+	return r.Min.X <= p.X && p.X <= r.Max.X && r.Min.Y <= p.Y && p.Y <= r.Max.Y
+}
+
+// ContainsRect reports whether s lies entirely within r.
+func (r Rect) ContainsRect(s Rect) bool {
+	return r.Contains(s.Min) && r.Contains(s.Max)
+}
+
+// Intersect returns the overlap of r and s. The boolean is false if they do not overlap.
+func (r Rect) Intersect(s Rect) (Rect, bool) {
+	overlap := Rect{
+		Min: Point2{max(r.Min.X, s.Min.X), max(r.Min.Y, s.Min.Y)},
+		Max: Point2{min(r.Max.X, s.Max.X), min(r.Max.Y, s.Max.Y)},
+	}
+	if overlap.Min.X > overlap.Max.X || overlap.Min.Y > overlap.Max.Y {
+		return Rect{}, false
+	}
+	return overlap, true
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDOT writes the graph in Graphviz DOT format. The label function names each
+// vertex; if it is nil, vertices are formatted with fmt.Sprint.
+func (g *Graph[K]) WriteDOT(w io.Writer, label func(K) string) error {
+	if label == nil {
+		label = func(v K) string { return fmt.Sprint(v) }
+	}
+
+	kind, arrow := "graph", "--"
+	if g.directed {
+		kind, arrow = "digraph", "->"
+	}
+
+	if _, err := fmt.Fprintf(w, "%s {\n", kind); err != nil {
+		return err
+	}
+	for i, v := range g.vertices {
+		if _, err := fmt.Fprintf(w, "\t%d [label=%s];\n", i, strconv.Quote(label(v))); err != nil {
+			return err
+		}
+	}
+	for i, edges := range g.edges {
+		for _, e := range edges {
+			j := g.index[e.To]
+			if !g.directed && j < i {
+				continue // each undirected edge is stored twice
+			}
+			if _, err := fmt.Fprintf(w, "\t%d %s %d [weight=%d];\n", i, arrow, j, e.Weight); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,426 @@
+// Package graph provides a small, dependency-free generic graph with adjacency lists.
+//
+// Vertices and edges are kept in insertion order, so every traversal and
+// algorithm in this package produces the same result on every run.
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrVertexNotFound is returned when an edge refers to a vertex that has not been added.
+var ErrVertexNotFound = errors.New("vertex not found")
+
+// CycleError is returned by TopologicalSort when the graph is not acyclic.
+type CycleError[K comparable] struct {
+	// Cycle lists the vertices of one cycle, starting and ending with the same vertex.
+	Cycle []K
+}
+
+func (e *CycleError[K]) Error() string {
+	return fmt.Sprintf("graph contains a cycle: %v", e.Cycle)
+}
+
+// Edge is a weighted edge to a neighbouring vertex.
+type Edge[K comparable] struct {
+	To     K
+	Weight int
+}
+
+// Graph is a directed or undirected graph with vertices of type K.
+type Graph[K comparable] struct {
+	directed bool
+	index    map[K]int
+	vertices []K
+	edges    [][]Edge[K] // outgoing edges, indexed like vertices
+	incoming [][]K       // incoming neighbours, only maintained for directed graphs
+}
+
+// New creates an empty undirected graph.
+func New[K comparable]() *Graph[K] {
+	return &Graph[K]{index: map[K]int{}}
+}
+
+// NewDirected creates an empty directed graph.
+func NewDirected[K comparable]() *Graph[K] {
+	return &Graph[K]{directed: true, index: map[K]int{}}
+}
+
+// Directed reports whether the graph is directed.
+func (g *Graph[K]) Directed() bool {
+	return g.directed
+}
+
+// AddVertex adds v to the graph. Adding an existing vertex is a no-op.
+func (g *Graph[K]) AddVertex(v K) {
+	if _, ok := g.index[v]; ok {
+		return
+	}
+	g.index[v] = len(g.vertices)
+	g.vertices = append(g.vertices, v)
+	g.edges = append(g.edges, nil)
+	g.incoming = append(g.incoming, nil)
+}
+
+// HasVertex reports whether v is in the graph.
+func (g *Graph[K]) HasVertex(v K) bool {
+	_, ok := g.index[v]
+	return ok
+}
+
+// AddEdge adds an edge of weight 1 between two existing vertices.
+func (g *Graph[K]) AddEdge(from, to K) error {
+	return g.AddWeightedEdge(from, to, 1)
+}
+
+// AddWeightedEdge adds an edge with the given weight between two existing vertices.
+// For undirected graphs the edge is added in both directions.
+func (g *Graph[K]) AddWeightedEdge(from, to K, weight int) error {
+	i, ok := g.index[from]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrVertexNotFound, from)
+	}
+	j, ok := g.index[to]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrVertexNotFound, to)
+	}
+
+	g.edges[i] = append(g.edges[i], Edge[K]{To: to, Weight: weight})
+	if g.directed {
+		g.incoming[j] = append(g.incoming[j], from)
+	} else if i != j {
+		g.edges[j] = append(g.edges[j], Edge[K]{To: from, Weight: weight})
+	}
+	return nil
+}
+
+// Order returns the number of vertices.
+func (g *Graph[K]) Order() int {
+	return len(g.vertices)
+}
+
+// Vertices returns the vertices in insertion order.
+func (g *Graph[K]) Vertices() []K {
+	return slices.Clone(g.vertices)
+}
+
+// Edges returns the outgoing edges of v in insertion order.
+func (g *Graph[K]) Edges(v K) []Edge[K] {
+	i, ok := g.index[v]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(g.edges[i])
+}
+
+// Neighbours returns the vertices reachable from v over a single edge.
+func (g *Graph[K]) Neighbours(v K) []K {
+	i, ok := g.index[v]
+	if !ok {
+		return nil
+	}
+	neighbours := make([]K, len(g.edges[i]))
+	for k, e := range g.edges[i] {
+		neighbours[k] = e.To
+	}
+	return neighbours
+}
+
+// BFS visits the vertices reachable from start in breadth-first order.
+// The traversal stops early when visit returns true.
+func (g *Graph[K]) BFS(start K, visit func(K) bool) error {
+	s, ok := g.index[start]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrVertexNotFound, start)
+	}
+
+	visited := make([]bool, len(g.vertices))
+	visited[s] = true
+	queue := []int{s}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visit(g.vertices[current]) {
+			return nil
+		}
+		for _, e := range g.edges[current] {
+			next := g.index[e.To]
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// DFS visits the vertices reachable from start in depth-first pre-order.
+// The traversal stops early when visit returns true.
+func (g *Graph[K]) DFS(start K, visit func(K) bool) error {
+	s, ok := g.index[start]
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrVertexNotFound, start)
+	}
+
+	visited := make([]bool, len(g.vertices))
+	stack := []int{s}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		if visit(g.vertices[current]) {
+			return nil
+		}
+
+		// Push in reverse so neighbours are visited in insertion order
+		edges := g.edges[current]
+		for k := len(edges) - 1; k >= 0; k-- {
+			if next := g.index[edges[k].To]; !visited[next] {
+				stack = append(stack, next)
+			}
+		}
+	}
+	return nil
+}
+
+// TopologicalSort orders the vertices of a directed graph so that for every edge
+// A -> B, A comes before B. Ties are broken by insertion order, so the result is stable.
+// If the graph has a cycle, the returned error is a *CycleError describing it.
+func (g *Graph[K]) TopologicalSort() ([]K, error) {
+	if !g.directed {
+		return nil, errors.New("topological sort requires a directed graph")
+	}
+
+	// Kahn's algorithm: repeatedly take vertices with no remaining incoming edges
+	inDegree := make([]int, len(g.vertices))
+	for i := range g.vertices {
+		inDegree[i] = len(g.incoming[i])
+	}
+
+	var queue []int
+	for i, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	order := make([]K, 0, len(g.vertices))
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		order = append(order, g.vertices[current])
+
+		for _, e := range g.edges[current] {
+			next := g.index[e.To]
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if len(order) != len(g.vertices) {
+		return nil, &CycleError[K]{Cycle: g.findCycle(inDegree)}
+	}
+	return order, nil
+}
+
+// findCycle walks backwards along incoming edges among the vertices that Kahn's
+// algorithm could not remove. Every such vertex has such an edge, so the walk must
+// eventually revisit a vertex, which closes a cycle.
+func (g *Graph[K]) findCycle(inDegree []int) []K {
+	current := slices.IndexFunc(inDegree, func(d int) bool { return d > 0 })
+	position := map[int]int{}
+	var walk []int
+	for {
+		if start, seen := position[current]; seen {
+			walk = append(walk[start:], current)
+			break
+		}
+		position[current] = len(walk)
+		walk = append(walk, current)
+		for _, from := range g.incoming[current] {
+			if p := g.index[from]; inDegree[p] > 0 {
+				current = p
+				break
+			}
+		}
+	}
+
+	// The walk followed edges backwards, so reverse it into edge direction
+	cycle := make([]K, len(walk))
+	for i, v := range walk {
+		cycle[len(walk)-1-i] = g.vertices[v]
+	}
+	return cycle
+}
+
+// ConnectedComponents returns the connected components of an undirected graph, or the
+// weakly connected components of a directed graph. Components are ordered by their first
+// vertex and list vertices in breadth-first order.
+func (g *Graph[K]) ConnectedComponents() [][]K {
+	visited := make([]bool, len(g.vertices))
+	var components [][]K
+	for s := range g.vertices {
+		if visited[s] {
+			continue
+		}
+
+		var component []K
+		visited[s] = true
+		queue := []int{s}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			component = append(component, g.vertices[current])
+
+			for _, e := range g.edges[current] {
+				if next := g.index[e.To]; !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+			for _, from := range g.incoming[current] {
+				if next := g.index[from]; !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// StronglyConnectedComponents returns the strongly connected components of a directed
+// graph using Tarjan's algorithm. Components are returned in reverse topological order.
+func (g *Graph[K]) StronglyConnectedComponents() [][]K {
+	n := len(g.vertices)
+	index := make([]int, n)
+	lowLink := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+
+	var stack []int
+	var components [][]K
+	counter := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		index[v] = counter
+		lowLink[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, e := range g.edges[v] {
+			w := g.index[e.To]
+			if index[w] == -1 {
+				strongConnect(w)
+				lowLink[v] = min(lowLink[v], lowLink[w])
+			} else if onStack[w] {
+				lowLink[v] = min(lowLink[v], index[w])
+			}
+		}
+
+		// v is the root of a component: pop it off the stack
+		if lowLink[v] == index[v] {
+			var component []K
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, g.vertices[w])
+				if w == v {
+					break
+				}
+			}
+			slices.Reverse(component)
+			components = append(components, component)
+		}
+	}
+
+	for v := range g.vertices {
+		if index[v] == -1 {
+			strongConnect(v)
+		}
+	}
+	return components
+}
+
+// ShortestPath returns a minimum-weight path from source to target and its total weight
+// using Dijkstra's algorithm. Edge weights must not be negative. The boolean is false if
+// target cannot be reached.
+func (g *Graph[K]) ShortestPath(source, target K) ([]K, int, bool) {
+	t, ok := g.index[target]
+	if !ok {
+		return nil, 0, false
+	}
+	dist, prev, ok := g.dijkstra(source)
+	if !ok || dist[t] < 0 {
+		return nil, 0, false
+	}
+
+	var path []K
+	for v := t; v != -1; v = prev[v] {
+		path = append(path, g.vertices[v])
+	}
+	slices.Reverse(path)
+	return path, dist[t], true
+}
+
+// ShortestDistances returns the minimum path weight from source to every reachable vertex.
+func (g *Graph[K]) ShortestDistances(source K) map[K]int {
+	dist, _, ok := g.dijkstra(source)
+	if !ok {
+		return nil
+	}
+	result := map[K]int{}
+	for v, d := range dist {
+		if d >= 0 {
+			result[g.vertices[v]] = d
+		}
+	}
+	return result
+}
+
+// dijkstra returns distances (-1 if unreachable) and predecessors (-1 for none) by vertex index.
+func (g *Graph[K]) dijkstra(source K) ([]int, []int, bool) {
+	s, ok := g.index[source]
+	if !ok {
+		return nil, nil, false
+	}
+
+	dist := make([]int, len(g.vertices))
+	prev := make([]int, len(g.vertices))
+	for i := range dist {
+		dist[i] = -1
+		prev[i] = -1
+	}
+	dist[s] = 0
+
+	queue := &priorityQueue{}
+	queue.push(s, 0)
+	for queue.len() > 0 {
+		current, d := queue.pop()
+		if d > dist[current] {
+			continue // stale entry
+		}
+		for _, e := range g.edges[current] {
+			next := g.index[e.To]
+			if nd := d + e.Weight; dist[next] < 0 || nd < dist[next] {
+				dist[next] = nd
+				prev[next] = current
+				queue.push(next, nd)
+			}
+		}
+	}
+	return dist, prev, true
+}
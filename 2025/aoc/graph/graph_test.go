@@ -0,0 +1,110 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// directed builds a directed graph on the given vertices from edges written as pairs.
+func directed(vertices string, edges ...string) *Graph[rune] {
+	g := NewDirected[rune]()
+	for _, v := range vertices {
+		g.AddVertex(v)
+	}
+	for _, e := range edges {
+		if err := g.AddEdge(rune(e[0]), rune(e[1])); err != nil {
+			panic(err)
+		}
+	}
+	return g
+}
+
+// hasEdge reports whether g has an edge from one vertex to another.
+func hasEdge(g *Graph[rune], from, to rune) bool {
+	return slices.Contains(g.Neighbours(from), to)
+}
+
+func TestTopologicalSort(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *Graph[rune]
+		want string
+	}{
+		{"empty", directed(""), ""},
+		{"no edges keeps insertion order", directed("cab"), "cab"},
+		{"chain", directed("abc", "bc", "ab"), "abc"},
+		{"chain added backwards", directed("cba", "ba", "cb"), "cba"},
+		{"diamond", directed("abcd", "ab", "ac", "bd", "cd"), "abcd"},
+		{"ties by insertion order", directed("dcba", "da", "ca"), "dcba"},
+		{"duplicate edges", directed("ab", "ab", "ab"), "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := tt.g.TopologicalSort()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(order); got != tt.want {
+				t.Errorf("TopologicalSort() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologicalSortCycles(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *Graph[rune]
+		want string // the vertices of the cycle, in any rotation
+	}{
+		{"self loop", directed("a", "aa"), "a"},
+		{"two vertices", directed("ab", "ab", "ba"), "ab"},
+		{"three vertices", directed("abc", "ab", "bc", "ca"), "abc"},
+		{"after an acyclic part", directed("xyabc", "xy", "ya", "ab", "bc", "ca"), "abc"},
+		{"with vertices after it", directed("abcde", "ab", "bc", "cb", "cd", "de"), "bc"},
+		{"beside a valid order", directed("pqab", "pq", "ab", "ba"), "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := tt.g.TopologicalSort()
+			var cycleErr *CycleError[rune]
+			if !errors.As(err, &cycleErr) {
+				t.Fatalf("TopologicalSort() = %s, %v, want a *CycleError", string(order), err)
+			}
+
+			cycle := cycleErr.Cycle
+			if len(cycle) < 2 || cycle[0] != cycle[len(cycle)-1] {
+				t.Fatalf("cycle %s does not end where it starts", string(cycle))
+			}
+			for i := 1; i < len(cycle); i++ {
+				if !hasEdge(tt.g, cycle[i-1], cycle[i]) {
+					t.Errorf("cycle %s follows %c -> %c, which is not an edge", string(cycle), cycle[i-1], cycle[i])
+				}
+			}
+			vertices := slices.Clone(cycle[1:])
+			slices.Sort(vertices)
+			if got := string(vertices); got != tt.want {
+				t.Errorf("cycle %s goes through %s, want %s", string(cycle), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologicalSortUndirected(t *testing.T) {
+	g := New[int]()
+	g.AddVertex(1)
+	if _, err := g.TopologicalSort(); err == nil {
+		t.Error("TopologicalSort() of an undirected graph succeeded")
+	}
+}
+
+func TestAddEdgeMissingVertex(t *testing.T) {
+	g := directed("ab")
+	if err := g.AddEdge('a', 'z'); !errors.Is(err, ErrVertexNotFound) {
+		t.Errorf("AddEdge() to a missing vertex: error = %v, want %v", err, ErrVertexNotFound)
+	}
+	if err := g.AddEdge('z', 'a'); !errors.Is(err, ErrVertexNotFound) {
+		t.Errorf("AddEdge() from a missing vertex: error = %v, want %v", err, ErrVertexNotFound)
+	}
+}
@@ -0,0 +1,40 @@
+package graph
+
+import "container/heap"
+
+// priorityQueue is a min-heap of vertex indices keyed by distance.
+type priorityQueue struct {
+	items []queueItem
+}
+
+type queueItem struct {
+	vertex   int
+	distance int
+}
+
+func (q *priorityQueue) push(vertex, distance int) {
+	heap.Push((*queueHeap)(q), queueItem{vertex, distance})
+}
+
+func (q *priorityQueue) pop() (int, int) {
+	item := heap.Pop((*queueHeap)(q)).(queueItem)
+	return item.vertex, item.distance
+}
+
+func (q *priorityQueue) len() int {
+	return len(q.items)
+}
+
+// queueHeap implements heap.Interface for priorityQueue.
+type queueHeap priorityQueue
+
+func (h *queueHeap) Len() int           { return len(h.items) }
+func (h *queueHeap) Less(i, j int) bool { return h.items[i].distance < h.items[j].distance }
+func (h *queueHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *queueHeap) Push(x any)         { h.items = append(h.items, x.(queueItem)) }
+
+func (h *queueHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
@@ -0,0 +1,306 @@
+// Package grid provides a generic 2D grid for character-map puzzles.
+package grid
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Point is a position on a grid. X is the column and Y is the row.
+type Point struct {
+	X, Y int
+}
+
+// Add returns the point shifted by d.
+func (p Point) Add(d Point) Point {
+	return Point{p.X + d.X, p.Y + d.Y}
+}
+
+// Directions used for neighbour iteration, starting up and going clockwise.
+var (
+	Up        = Point{0, -1}
+	UpRight   = Point{1, -1}
+	Right     = Point{1, 0}
+	DownRight = Point{1, 1}
+	Down      = Point{0, 1}
+	DownLeft  = Point{-1, 1}
+	Left      = Point{-1, 0}
+	UpLeft    = Point{-1, -1}
+)
+
+// Orthogonal holds the four orthogonal directions.
+var Orthogonal = []Point{Up, Right, Down, Left}
+
+// Adjacent holds all eight directions, including diagonals.
+var Adjacent = []Point{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+
+// Grid is a rectangular grid of cells stored in row-major order.
+type Grid[T any] struct {
+	width  int
+	height int
+	cells  []T
+}
+
+// New creates a width x height grid with every cell set to fill.
+func New[T any](width, height int, fill T) *Grid[T] {
+	cells := make([]T, width*height)
+	for i := range cells {
+		cells[i] = fill
+	}
+	return &Grid[T]{width: width, height: height, cells: cells}
+}
+
+// Parse builds a byte grid from lines of text. All lines must have the same length.
+func Parse(lines []string) (*Grid[byte], error) {
+	return parse(lines, func(line string) []byte { return []byte(line) })
+}
+
+// ParseRunes builds a rune grid from lines of text. All lines must have the same number of runes.
+func ParseRunes(lines []string) (*Grid[rune], error) {
+	return parse(lines, func(line string) []rune { return []rune(line) })
+}
+
+func parse[T any](lines []string, split func(string) []T) (*Grid[T], error) {
+	g := &Grid[T]{height: len(lines)}
+	for y, line := range lines {
+		row := split(line)
+		if y == 0 {
+			g.width = len(row)
+		} else if len(row) != g.width {
+			return nil, fmt.Errorf("line %d has width %d, expected %d", y+1, len(row), g.width)
+		}
+		g.cells = append(g.cells, row...)
+	}
+	return g, nil
+}
+
+// Width returns the number of columns.
+func (g *Grid[T]) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows.
+func (g *Grid[T]) Height() int {
+	return g.height
+}
+
+// InBounds reports whether p lies on the grid.
+func (g *Grid[T]) InBounds(p Point) bool {
+	return p.X >= 0 && p.X < g.width && p.Y >= 0 && p.Y < g.height
+}
+
+// At returns the cell at p. It panics if p is out of bounds.
+func (g *Grid[T]) At(p Point) T {
+	if !g.InBounds(p) {
+		panic(fmt.Sprintf("grid: point %v out of bounds %dx%d", p, g.width, g.height))
+	}
+	return g.cells[p.Y*g.width+p.X]
+}
+
+// Get returns the cell at p and whether p is on the grid.
+func (g *Grid[T]) Get(p Point) (T, bool) {
+	if !g.InBounds(p) {
+		var zero T
+		return zero, false
+	}
+	return g.cells[p.Y*g.width+p.X], true
+}
+
+// Set stores v at p. It panics if p is out of bounds.
+func (g *Grid[T]) Set(p Point, v T) {
+	if !g.InBounds(p) {
+		panic(fmt.Sprintf("grid: point %v out of bounds %dx%d", p, g.width, g.height))
+	}
+	g.cells[p.Y*g.width+p.X] = v
+}
+
+// Clone returns a deep copy of the grid.
+func (g *Grid[T]) Clone() *Grid[T] {
+	cells := make([]T, len(g.cells))
+	copy(cells, g.cells)
+	return &Grid[T]{width: g.width, height: g.height, cells: cells}
+}
+
+// Row returns row y as a slice sharing storage with the grid. It panics if y is out
+// of bounds.
+func (g *Grid[T]) Row(y int) []T {
+	if y < 0 || y >= g.height {
+		panic(fmt.Sprintf("grid: row %d out of bounds %dx%d", y, g.width, g.height))
+	}
+	return g.cells[y*g.width : (y+1)*g.width : (y+1)*g.width]
+}
+
+// Column returns a copy of column x. It panics if x is out of bounds.
+func (g *Grid[T]) Column(x int) []T {
+	if x < 0 || x >= g.width {
+		panic(fmt.Sprintf("grid: column %d out of bounds %dx%d", x, g.width, g.height))
+	}
+	column := make([]T, g.height)
+	for y := range column {
+		column[y] = g.cells[y*g.width+x]
+	}
+	return column
+}
+
+// All iterates over every cell in row-major order.
+func (g *Grid[T]) All() iter.Seq2[Point, T] {
+	return func(yield func(Point, T) bool) {
+		for i, v := range g.cells {
+			if !yield(Point{i % g.width, i / g.width}, v) {
+				return
+			}
+		}
+	}
+}
+
+// Neighbours4 iterates over the orthogonal neighbours of p that are on the grid.
+func (g *Grid[T]) Neighbours4(p Point) iter.Seq2[Point, T] {
+	return g.neighbours(p, Orthogonal)
+}
+
+// Neighbours8 iterates over the orthogonal and diagonal neighbours of p that are on the grid.
+func (g *Grid[T]) Neighbours8(p Point) iter.Seq2[Point, T] {
+	return g.neighbours(p, Adjacent)
+}
+
+func (g *Grid[T]) neighbours(p Point, directions []Point) iter.Seq2[Point, T] {
+	return func(yield func(Point, T) bool) {
+		for _, d := range directions {
+			n := p.Add(d)
+			if !g.InBounds(n) {
+				continue
+			}
+			if !yield(n, g.cells[n.Y*g.width+n.X]) {
+				return
+			}
+		}
+	}
+}
+
+// CountNeighbours8 counts the orthogonal and diagonal neighbours of p matching match.
+func (g *Grid[T]) CountNeighbours8(p Point, match func(T) bool) int {
+	count := 0
+	for _, v := range g.Neighbours8(p) {
+		if match(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// Find returns the first point in row-major order whose cell matches match.
+func (g *Grid[T]) Find(match func(T) bool) (Point, bool) {
+	for p, v := range g.All() {
+		if match(v) {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// FindAll returns every point whose cell matches match, in row-major order.
+func (g *Grid[T]) FindAll(match func(T) bool) []Point {
+	var points []Point
+	for p, v := range g.All() {
+		if match(v) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Count returns the number of cells matching match.
+func (g *Grid[T]) Count(match func(T) bool) int {
+	count := 0
+	for _, v := range g.cells {
+		if match(v) {
+			count++
+		}
+	}
+	return count
+}
+
+// RotateClockwise returns a copy of the grid rotated by 90 degrees clockwise.
+func (g *Grid[T]) RotateClockwise() *Grid[T] {
+	r := &Grid[T]{width: g.height, height: g.width, cells: make([]T, len(g.cells))}
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			r.cells[y*r.width+x] = g.cells[(g.height-1-x)*g.width+y]
+		}
+	}
+	return r
+}
+
+// RotateCounterClockwise returns a copy of the grid rotated by 90 degrees counter-clockwise.
+func (g *Grid[T]) RotateCounterClockwise() *Grid[T] {
+	r := &Grid[T]{width: g.height, height: g.width, cells: make([]T, len(g.cells))}
+	for y := 0; y < r.height; y++ {
+		for x := 0; x < r.width; x++ {
+			r.cells[y*r.width+x] = g.cells[x*g.width+(g.width-1-y)]
+		}
+	}
+	return r
+}
+
+// Rotate returns a copy of the grid rotated clockwise by degrees, which must be a multiple of 90.
+func (g *Grid[T]) Rotate(degrees int) *Grid[T] {
+	if degrees%90 != 0 {
+		panic(fmt.Sprintf("grid: cannot rotate by %d degrees", degrees))
+	}
+	switch (degrees%360 + 360) % 360 {
+	case 90:
+		return g.RotateClockwise()
+	case 180:
+		return g.FlipHorizontal().FlipVertical()
+	case 270:
+		return g.RotateCounterClockwise()
+	}
+	return g.Clone()
+}
+
+// FlipHorizontal returns a copy of the grid mirrored left to right.
+func (g *Grid[T]) FlipHorizontal() *Grid[T] {
+	r := g.Clone()
+	for y := 0; y < r.height; y++ {
+		row := r.Row(y)
+		for i, j := 0, len(row)-1; i < j; i, j = i+1, j-1 {
+			row[i], row[j] = row[j], row[i]
+		}
+	}
+	return r
+}
+
+// FlipVertical returns a copy of the grid mirrored top to bottom.
+func (g *Grid[T]) FlipVertical() *Grid[T] {
+	r := &Grid[T]{width: g.width, height: g.height, cells: make([]T, len(g.cells))}
+	for y := 0; y < g.height; y++ {
+		copy(r.Row(g.height-1-y), g.Row(y))
+	}
+	return r
+}
+
+// Format renders the grid one row per line, using cell to render each cell.
+func (g *Grid[T]) Format(cell func(T) string) string {
+	var sb strings.Builder
+	for y := 0; y < g.height; y++ {
+		for _, v := range g.Row(y) {
+			sb.WriteString(cell(v))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// String renders byte and rune grids as characters and any other grid with fmt.
+func (g *Grid[T]) String() string {
+	return g.Format(func(v T) string {
+		switch c := any(v).(type) {
+		case byte:
+			return string(rune(c))
+		case rune:
+			return string(c)
+		}
+		return fmt.Sprint(v)
+	})
+}
@@ -0,0 +1,159 @@
+package grid
+
+import (
+	"iter"
+	"testing"
+)
+
+// panics reports whether f panics.
+func panics(f func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	f()
+	return false
+}
+
+// length counts the pairs of seq.
+func length[K, V any](seq iter.Seq2[K, V]) int {
+	n := 0
+	for range seq {
+		n++
+	}
+	return n
+}
+
+func TestBounds(t *testing.T) {
+	g, err := Parse([]string{"abc", "def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		access func()
+		panics bool
+	}{
+		{"At inside", func() { g.At(Point{2, 1}) }, false},
+		{"At past the width", func() { g.At(Point{3, 0}) }, true},
+		{"At above", func() { g.At(Point{0, -1}) }, true},
+		{"Set past the height", func() { g.Set(Point{0, 2}, 'x') }, true},
+		{"Row first", func() { g.Row(0) }, false},
+		{"Row last", func() { g.Row(1) }, false},
+		{"Row past the height", func() { g.Row(2) }, true},
+		{"Row negative", func() { g.Row(-1) }, true},
+		{"Column first", func() { g.Column(0) }, false},
+		{"Column last", func() { g.Column(2) }, false},
+		{"Column past the width", func() { g.Column(3) }, true},
+		{"Column negative", func() { g.Column(-1) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := panics(tt.access); got != tt.panics {
+				t.Errorf("panicked = %v, want %v", got, tt.panics)
+			}
+		})
+	}
+
+	if got := string(g.Row(1)); got != "def" {
+		t.Errorf("Row(1) = %q, want %q", got, "def")
+	}
+	if got := string(g.Column(2)); got != "cf" {
+		t.Errorf("Column(2) = %q, want %q", got, "cf")
+	}
+	if _, ok := g.Get(Point{-1, 0}); ok {
+		t.Error("Get(-1, 0) is on the grid")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string // the grid, or the error
+	}{
+		{"empty", nil, ""},
+		{"rows", []string{"ab", "cd"}, "ab\ncd\n"},
+		{"ragged", []string{"abc", "abc", "ab"}, "line 3 has width 2, expected 3"},
+		{"longer", []string{"ab", "abc"}, "line 2 has width 3, expected 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Parse(tt.lines)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			} else {
+				got = g.String()
+			}
+			if got != tt.want {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	g, err := ParseRunes([]string{"éa", "bç"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width() != 2 || g.At(Point{1, 1}) != 'ç' {
+		t.Errorf("ParseRunes() = %dx%d grid %q", g.Width(), g.Height(), g)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	g, err := Parse([]string{"abc", "def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		transform func() *Grid[byte]
+		want      string
+	}{
+		{"clockwise", g.RotateClockwise, "da\neb\nfc\n"},
+		{"counter-clockwise", g.RotateCounterClockwise, "cf\nbe\nad\n"},
+		{"rotate 180", func() *Grid[byte] { return g.Rotate(180) }, "fed\ncba\n"},
+		{"rotate -90", func() *Grid[byte] { return g.Rotate(-90) }, "cf\nbe\nad\n"},
+		{"rotate 450", func() *Grid[byte] { return g.Rotate(450) }, "da\neb\nfc\n"},
+		{"rotate 0", func() *Grid[byte] { return g.Rotate(0) }, "abc\ndef\n"},
+		{"flip horizontal", g.FlipHorizontal, "cba\nfed\n"},
+		{"flip vertical", g.FlipVertical, "def\nabc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transform().String(); got != tt.want {
+				t.Errorf("got\n%swant\n%s", got, tt.want)
+			}
+		})
+	}
+	if got := g.String(); got != "abc\ndef\n" {
+		t.Errorf("the transforms changed the grid to\n%s", got)
+	}
+	if !panics(func() { g.Rotate(45) }) {
+		t.Error("Rotate(45) did not panic")
+	}
+}
+
+func TestNeighbours(t *testing.T) {
+	g := New(3, 3, '.')
+	g.Set(Point{1, 0}, '#')
+	g.Set(Point{2, 2}, '#')
+	tests := []struct {
+		p          Point
+		orthogonal int
+		all        int
+		walls      int
+	}{
+		{Point{0, 0}, 2, 3, 1},
+		{Point{1, 1}, 4, 8, 2},
+		{Point{2, 1}, 3, 5, 2},
+		{Point{2, 2}, 2, 3, 0},
+	}
+	for _, tt := range tests {
+		orthogonal, all := length(g.Neighbours4(tt.p)), length(g.Neighbours8(tt.p))
+		if orthogonal != tt.orthogonal || all != tt.all {
+			t.Errorf("%v has %d orthogonal and %d neighbours in all, want %d and %d", tt.p, orthogonal, all, tt.orthogonal, tt.all)
+		}
+		if got := g.CountNeighbours8(tt.p, func(c rune) bool { return c == '#' }); got != tt.walls {
+			t.Errorf("CountNeighbours8(%v) = %d, want %d", tt.p, got, tt.walls)
+		}
+	}
+}
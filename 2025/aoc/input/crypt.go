@@ -0,0 +1,96 @@
+package input
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"slices"
+)
+
+// An encrypted input starts with a header, which is authenticated along with the
+// ciphertext:
+//
+//	magic "aocenc1" | log2 of scrypt n | scrypt r | scrypt p | 16-byte salt | 12-byte nonce
+const magic = "aocenc1"
+
+const (
+	saltSize   = 16
+	headerSize = len(magic) + 3 + saltSize
+	keySize    = 32
+
+	// Cost of the key derivation: 2^15 rounds with r = 8 use 32 MiB and take about a tenth of a second.
+	defaultLogN = 15
+	defaultR    = 8
+	defaultP    = 1
+)
+
+// ErrDecrypt is returned when an encrypted input cannot be decrypted, either because the
+// passphrase is wrong or because the file is damaged.
+var ErrDecrypt = errors.New("wrong passphrase or damaged file")
+
+// Encrypt encrypts plain with a key derived from passphrase, using a fresh random salt and nonce.
+func Encrypt(plain []byte, passphrase string) ([]byte, error) {
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, defaultLogN, defaultR, defaultP)
+	salt := make([]byte, saltSize)
+	rand.Read(salt)
+	header = append(header, salt...)
+
+	aead, err := newAEAD(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	rand.Read(nonce)
+
+	out := append(header, nonce...)
+	// Seal appends to out, so the header and nonce it authenticates must be a copy
+	aad := slices.Clone(out)
+	return aead.Seal(out, nonce, plain, aad), nil
+}
+
+// Decrypt decrypts data produced by Encrypt with the same passphrase.
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, errors.New("not an encrypted input")
+	}
+	header := data[:headerSize]
+
+	aead, err := newAEAD(header, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce := data[headerSize : headerSize+aead.NonceSize()]
+	ciphertext := data[headerSize+aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, ciphertext, data[:headerSize+aead.NonceSize()])
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+// newAEAD derives the key described by a header and returns AES-256-GCM with it.
+func newAEAD(header []byte, passphrase string) (cipher.AEAD, error) {
+	params := header[len(magic):]
+	logN, r, p := int(params[0]), int(params[1]), int(params[2])
+	if logN < 1 || logN > 24 {
+		return nil, errors.New("invalid key derivation cost")
+	}
+	salt := params[3 : 3+saltSize]
+
+	key, err := scrypt(passphrase, salt, 1<<logN, r, p, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
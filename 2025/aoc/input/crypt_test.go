@@ -0,0 +1,173 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		plain string
+	}{
+		{"empty", ""},
+		{"line", "L68\n"},
+		{"several lines", "123-456\n789-1011\n\n5\n17\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := Encrypt([]byte(tt.plain), "correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.plain) > 0 && bytes.Contains(encrypted, []byte(tt.plain)) {
+				t.Error("the encrypted input contains the plain text")
+			}
+			plain, err := Decrypt(encrypted, "correct horse")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(plain) != tt.plain {
+				t.Errorf("Decrypt(Encrypt(%q)) = %q", tt.plain, plain)
+			}
+		})
+	}
+}
+
+func TestEncryptFreshSalt(t *testing.T) {
+	first, err := Encrypt([]byte("same input"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Encrypt([]byte("same input"), "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first, second) {
+		t.Error("encrypting twice gave the same bytes")
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	encrypted, err := Encrypt([]byte("1,2\n3,4\n"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	damaged := func(i int) []byte {
+		data := bytes.Clone(encrypted)
+		data[i] ^= 1
+		return data
+	}
+	// A header asking for a key derivation cost of 2^0, followed by a zero salt and nonce
+	invalidCost := append([]byte(magic), 0, defaultR, defaultP)
+	invalidCost = append(invalidCost, make([]byte, saltSize+12)...)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		want       error // nil for any error other than ErrDecrypt
+	}{
+		{"wrong passphrase", encrypted, "battery staple", ErrDecrypt},
+		{"empty passphrase", encrypted, "", ErrDecrypt},
+		{"damaged salt", damaged(len(magic) + 3), "correct horse", ErrDecrypt},
+		{"damaged nonce", damaged(headerSize), "correct horse", ErrDecrypt},
+		{"damaged ciphertext", damaged(len(encrypted) - 1), "correct horse", ErrDecrypt},
+		{"truncated", encrypted[:headerSize+4], "correct horse", ErrDecrypt},
+		{"plain input", []byte("1,2\n3,4\n"), "correct horse", nil},
+		{"invalid cost", invalidCost, "correct horse", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, err := Decrypt(tt.data, tt.passphrase)
+			if err == nil {
+				t.Fatalf("Decrypt() = %q, want an error", plain)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && errors.Is(err, ErrDecrypt) {
+				t.Errorf("Decrypt() error = %v, want an error about the format", err)
+			}
+		})
+	}
+}
+
+func TestOpenEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "input2.txt")
+	encrypted, err := Encrypt([]byte("L68\nR48\n"), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name+Ext, encrypted, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(KeyEnv, "")
+	if _, err := ReadFile(name); !errors.Is(err, ErrNoKey) {
+		t.Errorf("ReadFile() without a passphrase: error = %v, want %v", err, ErrNoKey)
+	}
+	if err := Available(name); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Available() without a passphrase = %v, want %v", err, ErrNoKey)
+	}
+
+	t.Setenv(KeyEnv, "battery staple")
+	if _, err := ReadFile(name); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("ReadFile() with the wrong passphrase: error = %v, want %v", err, ErrDecrypt)
+	}
+
+	t.Setenv(KeyEnv, "correct horse")
+	plain, err := ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != "L68\nR48\n" {
+		t.Errorf("ReadFile() = %q", plain)
+	}
+	if err := Available(name); err != nil {
+		t.Errorf("Available() = %v", err)
+	}
+}
+
+// TestDecryptCommittedInputs checks that every input committed encrypted next to the
+// days decrypts with the passphrase in KeyEnv.
+func TestDecryptCommittedInputs(t *testing.T) {
+	passphrase := os.Getenv(KeyEnv)
+	if passphrase == "" {
+		t.Skip(ErrNoKey)
+	}
+	var names []string
+	err := filepath.WalkDir("../..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != "../.." {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(path, Ext) {
+			names = append(names, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Skip("no encrypted inputs are committed")
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Decrypt(data, passphrase); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
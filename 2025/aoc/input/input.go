@@ -0,0 +1,106 @@
+// Package input opens puzzle inputs, which may be committed encrypted.
+//
+// Puzzle inputs must not be published in the clear. An input can instead be
+// committed as input2.txt.enc, encrypted with AES-GCM under a key derived from a
+// passphrase with scrypt. Open falls back to the encrypted file when the plain one
+// is missing and decrypts it in memory with the passphrase in $AOC_INPUT_KEY:
+//
+//	file, err := input.Open("input2.txt") // reads input2.txt, or decrypts input2.txt.enc
+//
+// Without the passphrase, Open fails with ErrNoKey, and the runner skips such inputs.
+//
+// Lines and Columns stream inputs too large to hold in memory.
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// KeyEnv is the environment variable holding the passphrase of encrypted inputs.
+const KeyEnv = "AOC_INPUT_KEY"
+
+// Ext is the extension added to the name of an encrypted input.
+const Ext = ".enc"
+
+// ErrNoKey is returned for encrypted inputs when no passphrase is set in KeyEnv.
+var ErrNoKey = errors.New("input is encrypted and " + KeyEnv + " is not set")
+
+// Open opens the named input for reading. If it does not exist but an encrypted copy
+// with the Ext suffix does, the copy is decrypted with the passphrase in KeyEnv.
+func Open(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return file, err
+	}
+
+	encrypted, encErr := os.ReadFile(name + Ext)
+	if encErr != nil {
+		// Report the plain file as missing rather than its encrypted copy
+		return nil, err
+	}
+	passphrase := os.Getenv(KeyEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("%s: %w", name, ErrNoKey)
+	}
+	plain, err := Decrypt(encrypted, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s%s: %w", name, Ext, err)
+	}
+	return decrypted{bytes.NewReader(plain)}, nil
+}
+
+// decrypted is an input decrypted in memory. Like a file, it can be read at any offset.
+type decrypted struct {
+	*bytes.Reader
+}
+
+func (decrypted) Close() error {
+	return nil
+}
+
+// ReadFile reads the whole named input, decrypting it like Open if needed.
+func ReadFile(name string) ([]byte, error) {
+	file, err := Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// Available reports whether Open could read the named input without decrypting it:
+// nil if it exists in the clear or encrypted with a passphrase set, ErrNoKey if only
+// the encrypted copy exists and no passphrase is set, or the error from os.Stat.
+func Available(name string) error {
+	_, err := os.Stat(name)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if _, encErr := os.Stat(name + Ext); encErr != nil {
+		return err
+	}
+	if os.Getenv(KeyEnv) == "" {
+		return fmt.Errorf("%s: %w", name, ErrNoKey)
+	}
+	return nil
+}
+
+// Size returns the size in bytes of the named input, or of its encrypted copy if only
+// that exists, which is a little larger than the input it holds.
+func Size(name string) (int64, error) {
+	info, err := os.Stat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if encrypted, encErr := os.Stat(name + Ext); encErr == nil {
+			return encrypted.Size(), nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,127 @@
+package input
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// scrypt derives a key from a passphrase with the memory-hard scrypt function of RFC 7914.
+// n is the CPU and memory cost and must be a power of two; the work uses 128*n*r bytes.
+func scrypt(passphrase string, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n&(n-1) != 0 {
+		return nil, errors.New("scrypt: n must be a power of two greater than 1")
+	}
+	if r <= 0 || p <= 0 || r*p >= 1<<30 || n > 1<<30/r {
+		return nil, errors.New("scrypt: parameters are too large")
+	}
+
+	blocks, err := pbkdf2.Key(sha256.New, passphrase, salt, 1, p*128*r)
+	if err != nil {
+		return nil, err
+	}
+
+	words := 32 * r
+	x := make([]uint32, words)
+	y := make([]uint32, words)
+	v := make([]uint32, words*n)
+	for i := range p {
+		block := blocks[i*128*r : (i+1)*128*r]
+		for k := range x {
+			x[k] = binary.LittleEndian.Uint32(block[4*k:])
+		}
+		roMix(x, y, v, n, r)
+		for k := range x {
+			binary.LittleEndian.PutUint32(block[4*k:], x[k])
+		}
+	}
+
+	return pbkdf2.Key(sha256.New, passphrase, blocks, 1, keyLen)
+}
+
+// roMix mixes the block x in place, using v as the n-block scratch table and y as a
+// block of scratch space.
+func roMix(x, y, v []uint32, n, r int) {
+	words := 32 * r
+	for i := range n {
+		copy(v[i*words:], x)
+		blockMix(x, y, r)
+	}
+	for range n {
+		j := int(x[words-16]) & (n - 1)
+		for k, w := range v[j*words : (j+1)*words] {
+			x[k] ^= w
+		}
+		blockMix(x, y, r)
+	}
+}
+
+// blockMix applies Salsa20/8 to the 2r 64-byte chunks of b in sequence, storing the
+// even-numbered outputs before the odd-numbered ones.
+func blockMix(b, y []uint32, r int) {
+	var t [16]uint32
+	copy(t[:], b[(2*r-1)*16:])
+	for i := range 2 * r {
+		for k := range t {
+			t[k] ^= b[i*16+k]
+		}
+		salsa208(&t)
+		half := i / 2
+		if i%2 == 1 {
+			half += r
+		}
+		copy(y[half*16:], t[:])
+	}
+	copy(b, y)
+}
+
+// salsa208 applies the Salsa20/8 core to a 64-byte block.
+func salsa208(b *[16]uint32) {
+	x := *b
+	for range 4 {
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+	for i := range b {
+		b[i] += x[i]
+	}
+}
@@ -0,0 +1,71 @@
+package input
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// The test vectors of RFC 7914, section 12. The last one, with n = 2^20, needs 1 GiB
+// and is left out.
+func TestScrypt(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		salt       string
+		n, r, p    int
+		key        string
+	}{
+		{
+			passphrase: "",
+			salt:       "",
+			n:          16, r: 1, p: 1,
+			key: "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442" +
+				"fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906",
+		},
+		{
+			passphrase: "password",
+			salt:       "NaCl",
+			n:          1024, r: 8, p: 16,
+			key: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b373162" +
+				"2eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+		{
+			passphrase: "pleaseletmein",
+			salt:       "SodiumChloride",
+			n:          16384, r: 8, p: 1,
+			key: "7023bdcb3afd7348461c06cd81fd38ebfda8fbba904f8e3ea9b543f6545da1f2" +
+				"d5432955613f0fcf62d49705242a9af9e61e85dc0d651e40dfcf017b45575887",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("n=%d,r=%d,p=%d", tt.n, tt.r, tt.p), func(t *testing.T) {
+			key, err := scrypt(tt.passphrase, []byte(tt.salt), tt.n, tt.r, tt.p, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(key); got != tt.key {
+				t.Errorf("scrypt(%q, %q, %d, %d, %d) =\n%s\nwant\n%s", tt.passphrase, tt.salt, tt.n, tt.r, tt.p, got, tt.key)
+			}
+		})
+	}
+}
+
+func TestScryptInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		n, r, p int
+	}{
+		{"n not a power of two", 1000, 8, 1},
+		{"n of 1", 1, 8, 1},
+		{"zero r", 16, 0, 1},
+		{"zero p", 16, 8, 0},
+		{"too much memory", 1 << 30, 8, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := scrypt("password", []byte("salt"), tt.n, tt.r, tt.p, 32); err == nil {
+				t.Errorf("scrypt(n=%d, r=%d, p=%d) succeeded, want an error", tt.n, tt.r, tt.p)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"iter"
+	"math"
+)
+
+// maxLineSize is the longest line Lines reads. The buffer only grows that large for
+// inputs with such lines.
+const maxLineSize = 64 << 20
+
+// Lines streams the lines of the named input, reading them as the sequence is iterated
+// so that the input is never held in memory, unless it is encrypted. Lines may be up
+// to 64 MiB long. Once the sequence ends, the returned function reports any error met
+// opening or reading the input.
+func Lines(name string) (iter.Seq[string], func() error) {
+	var err error
+	seq := func(yield func(string) bool) {
+		var file io.ReadCloser
+		file, err = Open(name)
+		if err != nil {
+			return
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, maxLineSize)
+		for scanner.Scan() {
+			if !yield(scanner.Text()) {
+				return
+			}
+		}
+		err = scanner.Err()
+	}
+	return seq, func() error { return err }
+}
+
+// Columns streams the columns of the named input, for inputs laid out in columns whose
+// lines are too long to hold in memory. A column has a byte for each line, a space
+// for lines shorter than the longest one. The yielded slice is reused, so it must be
+// copied to be kept. Once the sequence ends, the returned function reports any error.
+//
+// The input is opened once, and decrypted once if it is encrypted. It is read once to
+// find where its lines start, then once more with a reader per line sharing it, so
+// memory only grows with the number of lines.
+func Columns(name string) (iter.Seq[[]byte], func() error) {
+	var err error
+	seq := func(yield func([]byte) bool) {
+		var file io.ReadCloser
+		file, err = Open(name)
+		if err != nil {
+			return
+		}
+		defer file.Close()
+
+		// Files and decrypted inputs can both be read at any offset
+		at, ok := file.(io.ReaderAt)
+		if !ok {
+			var data []byte
+			if data, err = io.ReadAll(file); err != nil {
+				return
+			}
+			at = bytes.NewReader(data)
+		}
+
+		var starts []int64
+		starts, err = lineStarts(io.NewSectionReader(at, 0, math.MaxInt64))
+		if err != nil {
+			return
+		}
+
+		rows := make([]*bufio.Reader, len(starts))
+		for i, start := range starts {
+			rows[i] = bufio.NewReader(io.NewSectionReader(at, start, math.MaxInt64-start))
+		}
+
+		ended := make([]bool, len(rows))
+		column := make([]byte, len(rows))
+		for {
+			more := false
+			for i, row := range rows {
+				column[i] = ' '
+				if ended[i] {
+					continue
+				}
+				c, readErr := row.ReadByte()
+				if readErr == io.EOF || c == '\n' || c == '\r' {
+					ended[i] = true
+					continue
+				}
+				if readErr != nil {
+					err = readErr
+					return
+				}
+				column[i] = c
+				more = true
+			}
+			if !more || !yield(column) {
+				return
+			}
+		}
+	}
+	return seq, func() error { return err }
+}
+
+// lineStarts returns the offset of every line read from r.
+func lineStarts(r io.Reader) ([]int64, error) {
+	var starts []int64
+	var offset int64
+	buffer := make([]byte, 64*1024)
+	atLineStart := true
+	for {
+		n, err := r.Read(buffer)
+		for chunk := buffer[:n]; len(chunk) > 0; {
+			if atLineStart {
+				starts = append(starts, offset)
+			}
+			end := bytes.IndexByte(chunk, '\n')
+			if end < 0 {
+				offset += int64(len(chunk))
+				atLineStart = false
+				break
+			}
+			offset += int64(end + 1)
+			chunk = chunk[end+1:]
+			atLineStart = true
+		}
+		if err == io.EOF {
+			return starts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
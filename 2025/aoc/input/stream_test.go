@@ -0,0 +1,75 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	long := strings.Repeat("7", 200*1024)
+	tests := []struct {
+		name  string
+		text  string
+		lines []string
+	}{
+		{"empty", "", nil},
+		{"no final newline", "1,2\n3,4", []string{"1,2", "3,4"}},
+		{"blank line", "1-3\n\n5\n", []string{"1-3", "", "5"}},
+		{"line past the scanner default", "a\n" + long + "\nb\n", []string{"a", long, "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "input.txt")
+			if err := os.WriteFile(name, []byte(tt.text), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			lines, linesErr := Lines(name)
+			got := slices.Collect(lines)
+			if err := linesErr(); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.lines) {
+				t.Errorf("Lines() = %d lines, want %d", len(got), len(tt.lines))
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	const text = "123 328\n 45 64\n*   +  \n"
+	want := []string{"1 *", "24 ", "35 ", "   ", "36+", "24 ", "8  "}
+
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.txt")
+	if err := os.WriteFile(plain, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := filepath.Join(dir, "encrypted.txt")
+	data, err := Encrypt([]byte(text), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(encrypted+Ext, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(KeyEnv, "correct horse")
+
+	for _, name := range []string{plain, encrypted} {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			columns, columnsErr := Columns(name)
+			var got []string
+			for column := range columns {
+				got = append(got, string(column))
+			}
+			if err := columnsErr(); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Columns() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Package interval implements sets of integers stored as sorted, disjoint inclusive intervals.
+//
+// Sets are available with int64 endpoints (New) and with arbitrary precision
+// *big.Int endpoints (NewBig). Both share the same algorithms.
+package interval
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Interval is the inclusive range of integers [Lo, Hi].
+type Interval[T any] struct {
+	Lo, Hi T
+}
+
+// String formats the interval as "Lo-Hi".
+func (iv Interval[T]) String() string {
+	return fmt.Sprintf("%v-%v", iv.Lo, iv.Hi)
+}
+
+// arithmetic abstracts the endpoint type of a Set.
+type arithmetic[T any] interface {
+	compare(a, b T) int
+	// succ returns a+1, or false if a is the largest representable value.
+	succ(a T) (T, bool)
+	// pred returns a-1, or false if a is the smallest representable value.
+	pred(a T) (T, bool)
+	// length returns the number of integers in [lo, hi].
+	length(lo, hi T) T
+	add(a, b T) T
+	zero() T
+	// clone returns a copy of a that the caller cannot change through a.
+	clone(a T) T
+}
+
+// Set is a set of integers. The zero value is not usable; create sets with New or NewBig.
+type Set[T any] struct {
+	ops       arithmetic[T]
+	intervals []Interval[T] // sorted, disjoint and never adjacent
+}
+
+// New returns the int64 set covering the union of the given intervals.
+// Intervals with Lo > Hi are ignored.
+func New(intervals ...Interval[int64]) *Set[int64] {
+	return newSet[int64](int64Arithmetic{}, intervals)
+}
+
+// NewBig returns the arbitrary precision set covering the union of the given intervals.
+// Intervals with Lo > Hi are ignored. The endpoints are copied.
+func NewBig(intervals ...Interval[*big.Int]) *Set[*big.Int] {
+	ops := bigArithmetic{}
+	copied := make([]Interval[*big.Int], len(intervals))
+	for i, iv := range intervals {
+		copied[i] = Interval[*big.Int]{ops.clone(iv.Lo), ops.clone(iv.Hi)}
+	}
+	return newSet[*big.Int](ops, copied)
+}
+
+func newSet[T any](ops arithmetic[T], intervals []Interval[T]) *Set[T] {
+	s := &Set[T]{ops: ops}
+	s.intervals = s.merge(intervals)
+	return s
+}
+
+// merge sorts the intervals and joins overlapping or adjacent ones in O(n log n).
+func (s *Set[T]) merge(intervals []Interval[T]) []Interval[T] {
+	sorted := make([]Interval[T], 0, len(intervals))
+	for _, iv := range intervals {
+		if s.ops.compare(iv.Lo, iv.Hi) <= 0 {
+			sorted = append(sorted, iv)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b Interval[T]) int {
+		return s.ops.compare(a.Lo, b.Lo)
+	})
+
+	var merged []Interval[T]
+	for _, iv := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && s.touches(merged[last], iv) {
+			if s.ops.compare(iv.Hi, merged[last].Hi) > 0 {
+				merged[last].Hi = iv.Hi
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// touches reports whether b, which starts no earlier than a, overlaps or is adjacent to a.
+func (s *Set[T]) touches(a, b Interval[T]) bool {
+	next, ok := s.ops.succ(a.Hi)
+	return !ok || s.ops.compare(b.Lo, next) <= 0
+}
+
+// Insert adds the interval to the set, merging it with any intervals it overlaps or touches.
+// The endpoints are copied.
+func (s *Set[T]) Insert(iv Interval[T]) {
+	if s.ops.compare(iv.Lo, iv.Hi) > 0 {
+		return
+	}
+	iv = Interval[T]{s.ops.clone(iv.Lo), s.ops.clone(iv.Hi)}
+
+	// First interval that could merge with iv: its end is at least iv.Lo-1
+	start := sort.Search(len(s.intervals), func(i int) bool {
+		return s.touches(s.intervals[i], iv)
+	})
+	// First interval that starts after iv and is not adjacent to it
+	end := start
+	for end < len(s.intervals) && s.touches(iv, s.intervals[end]) {
+		end++
+	}
+
+	merged := iv
+	if start < end {
+		if s.ops.compare(s.intervals[start].Lo, merged.Lo) < 0 {
+			merged.Lo = s.intervals[start].Lo
+		}
+		if s.ops.compare(s.intervals[end-1].Hi, merged.Hi) > 0 {
+			merged.Hi = s.intervals[end-1].Hi
+		}
+	}
+	s.intervals = slices.Replace(s.intervals, start, end, merged)
+}
+
+// Contains reports whether x is in the set, using binary search.
+func (s *Set[T]) Contains(x T) bool {
+	_, ok := s.Find(x)
+	return ok
+}
+
+// Find returns the interval of the set containing x, using binary search.
+func (s *Set[T]) Find(x T) (Interval[T], bool) {
+	i := sort.Search(len(s.intervals), func(i int) bool {
+		return s.ops.compare(s.intervals[i].Hi, x) >= 0
+	})
+	if i < len(s.intervals) && s.ops.compare(s.intervals[i].Lo, x) <= 0 {
+		return s.intervals[i], true
+	}
+	return Interval[T]{}, false
+}
+
+// Len returns the number of integers covered by the set.
+// For int64 sets the result overflows if more than math.MaxInt64 integers are covered.
+func (s *Set[T]) Len() T {
+	total := s.ops.zero()
+	for _, iv := range s.intervals {
+		total = s.ops.add(total, s.ops.length(iv.Lo, iv.Hi))
+	}
+	return total
+}
+
+// Count returns the number of disjoint intervals in the set.
+func (s *Set[T]) Count() int {
+	return len(s.intervals)
+}
+
+// All iterates over the disjoint intervals of the set in ascending order.
+func (s *Set[T]) All() iter.Seq[Interval[T]] {
+	return slices.Values(s.intervals)
+}
+
+// Intervals returns a copy of the disjoint intervals of the set in ascending order.
+func (s *Set[T]) Intervals() []Interval[T] {
+	return slices.Clone(s.intervals)
+}
+
+// Union returns a new set containing the integers in s or other.
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	return newSet(s.ops, append(slices.Clone(s.intervals), other.intervals...))
+}
+
+// Intersect returns a new set containing the integers in both s and other.
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	result := &Set[T]{ops: s.ops}
+	i, j := 0, 0
+	for i < len(s.intervals) && j < len(other.intervals) {
+		a, b := s.intervals[i], other.intervals[j]
+		lo, hi := a.Lo, a.Hi
+		if s.ops.compare(b.Lo, lo) > 0 {
+			lo = b.Lo
+		}
+		if s.ops.compare(b.Hi, hi) < 0 {
+			hi = b.Hi
+		}
+		if s.ops.compare(lo, hi) <= 0 {
+			result.intervals = append(result.intervals, Interval[T]{lo, hi})
+		}
+
+		// Advance whichever interval ends first
+		if s.ops.compare(a.Hi, b.Hi) < 0 {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// Difference returns a new set containing the integers in s that are not in other.
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	result := &Set[T]{ops: s.ops}
+	j := 0
+	for _, iv := range s.intervals {
+		lo := iv.Lo
+		exhausted := false
+
+		// Skip intervals of other that end before this one starts
+		for j < len(other.intervals) && s.ops.compare(other.intervals[j].Hi, lo) < 0 {
+			j++
+		}
+
+		for k := j; k < len(other.intervals) && s.ops.compare(other.intervals[k].Lo, iv.Hi) <= 0; k++ {
+			cut := other.intervals[k]
+			if s.ops.compare(cut.Lo, lo) > 0 {
+				hi, _ := s.ops.pred(cut.Lo)
+				result.intervals = append(result.intervals, Interval[T]{lo, hi})
+			}
+			next, ok := s.ops.succ(cut.Hi)
+			if !ok || s.ops.compare(next, iv.Hi) > 0 {
+				exhausted = true
+				break
+			}
+			lo = next
+		}
+
+		if !exhausted {
+			result.intervals = append(result.intervals, Interval[T]{lo, iv.Hi})
+		}
+	}
+	return result
+}
+
+// Complement returns a new set containing the integers within bounds that are not in s.
+func (s *Set[T]) Complement(bounds Interval[T]) *Set[T] {
+	return newSet(s.ops, []Interval[T]{bounds}).Difference(s)
+}
+
+// String formats the set as a list of intervals, e.g. "{3-5, 10-20}".
+func (s *Set[T]) String() string {
+	parts := make([]string, len(s.intervals))
+	for i, iv := range s.intervals {
+		parts[i] = iv.String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+type int64Arithmetic struct{}
+
+func (int64Arithmetic) compare(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (int64Arithmetic) succ(a int64) (int64, bool) {
+	return a + 1, a != math.MaxInt64
+}
+
+func (int64Arithmetic) pred(a int64) (int64, bool) {
+	return a - 1, a != math.MinInt64
+}
+
+func (int64Arithmetic) length(lo, hi int64) int64 {
+	return hi - lo + 1
+}
+
+func (int64Arithmetic) add(a, b int64) int64 {
+	return a + b
+}
+
+func (int64Arithmetic) zero() int64 {
+	return 0
+}
+
+func (int64Arithmetic) clone(a int64) int64 {
+	return a
+}
+
+var bigOne = big.NewInt(1)
+
+type bigArithmetic struct{}
+
+func (bigArithmetic) compare(a, b *big.Int) int {
+	return a.Cmp(b)
+}
+
+func (bigArithmetic) succ(a *big.Int) (*big.Int, bool) {
+	return new(big.Int).Add(a, bigOne), true
+}
+
+func (bigArithmetic) pred(a *big.Int) (*big.Int, bool) {
+	return new(big.Int).Sub(a, bigOne), true
+}
+
+func (bigArithmetic) length(lo, hi *big.Int) *big.Int {
+	n := new(big.Int).Sub(hi, lo)
+	return n.Add(n, bigOne)
+}
+
+func (bigArithmetic) add(a, b *big.Int) *big.Int {
+	return new(big.Int).Add(a, b)
+}
+
+func (bigArithmetic) zero() *big.Int {
+	return new(big.Int)
+}
+
+func (bigArithmetic) clone(a *big.Int) *big.Int {
+	return new(big.Int).Set(a)
+}
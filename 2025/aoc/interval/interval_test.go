@@ -0,0 +1,268 @@
+package interval
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// iv returns the int64 interval [lo, hi].
+func iv(lo, hi int64) Interval[int64] {
+	return Interval[int64]{lo, hi}
+}
+
+// bigSet returns the arbitrary precision set of the same intervals as s.
+func bigSet(s *Set[int64]) *Set[*big.Int] {
+	var intervals []Interval[*big.Int]
+	for i := range s.All() {
+		intervals = append(intervals, Interval[*big.Int]{big.NewInt(i.Lo), big.NewInt(i.Hi)})
+	}
+	return NewBig(intervals...)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []Interval[int64]
+		want      string
+		len       int64
+	}{
+		{"no intervals", nil, "{}", 0},
+		{"empty interval", []Interval[int64]{iv(5, 4)}, "{}", 0},
+		{"single point", []Interval[int64]{iv(7, 7)}, "{7-7}", 1},
+		{"overlapping", []Interval[int64]{iv(10, 14), iv(12, 18)}, "{10-18}", 9},
+		{"touching", []Interval[int64]{iv(1, 3), iv(4, 6)}, "{1-6}", 6},
+		{"one apart", []Interval[int64]{iv(1, 3), iv(5, 6)}, "{1-3, 5-6}", 5},
+		{"nested", []Interval[int64]{iv(1, 20), iv(5, 6)}, "{1-20}", 20},
+		{"unsorted with an empty one", []Interval[int64]{iv(16, 20), iv(9, 1), iv(3, 5), iv(10, 14)}, "{3-5, 10-14, 16-20}", 13},
+		{"negative", []Interval[int64]{iv(-5, -1), iv(0, 2)}, "{-5-2}", 8},
+		{"up to the largest int64", []Interval[int64]{iv(math.MaxInt64-1, math.MaxInt64), iv(math.MaxInt64, math.MaxInt64)}, fmt.Sprintf("{%d-%d}", int64(math.MaxInt64-1), int64(math.MaxInt64)), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.intervals...)
+			if got := s.String(); got != tt.want {
+				t.Errorf("New() = %s, want %s", got, tt.want)
+			}
+			if got := s.Len(); got != tt.len {
+				t.Errorf("Len() = %d, want %d", got, tt.len)
+			}
+			if got := bigSet(s).String(); got != tt.want {
+				t.Errorf("NewBig() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	s := New(iv(3, 5), iv(10, 20))
+	tests := []struct {
+		x    int64
+		want Interval[int64]
+		ok   bool
+	}{
+		{2, Interval[int64]{}, false},
+		{3, iv(3, 5), true},
+		{5, iv(3, 5), true},
+		{6, Interval[int64]{}, false},
+		{15, iv(10, 20), true},
+		{21, Interval[int64]{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := s.Find(tt.x)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Find(%d) = %v, %v, want %v, %v", tt.x, got, ok, tt.want, tt.ok)
+		}
+		if s.Contains(tt.x) != tt.ok {
+			t.Errorf("Contains(%d) = %v, want %v", tt.x, !tt.ok, tt.ok)
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         []Interval[int64]
+		union        string
+		intersection string
+		difference   string // a without b
+	}{
+		{
+			name:         "both empty",
+			union:        "{}",
+			intersection: "{}",
+			difference:   "{}",
+		},
+		{
+			name:         "other empty",
+			a:            []Interval[int64]{iv(1, 5)},
+			union:        "{1-5}",
+			intersection: "{}",
+			difference:   "{1-5}",
+		},
+		{
+			name:         "only an empty interval",
+			a:            []Interval[int64]{iv(1, 5)},
+			b:            []Interval[int64]{iv(3, 2)},
+			union:        "{1-5}",
+			intersection: "{}",
+			difference:   "{1-5}",
+		},
+		{
+			name:         "disjoint",
+			a:            []Interval[int64]{iv(1, 3)},
+			b:            []Interval[int64]{iv(7, 9)},
+			union:        "{1-3, 7-9}",
+			intersection: "{}",
+			difference:   "{1-3}",
+		},
+		{
+			name:         "touching",
+			a:            []Interval[int64]{iv(1, 3)},
+			b:            []Interval[int64]{iv(4, 6)},
+			union:        "{1-6}",
+			intersection: "{}",
+			difference:   "{1-3}",
+		},
+		{
+			name:         "sharing an endpoint",
+			a:            []Interval[int64]{iv(1, 5)},
+			b:            []Interval[int64]{iv(5, 9)},
+			union:        "{1-9}",
+			intersection: "{5-5}",
+			difference:   "{1-4}",
+		},
+		{
+			name:         "overlapping",
+			a:            []Interval[int64]{iv(1, 10)},
+			b:            []Interval[int64]{iv(5, 15)},
+			union:        "{1-15}",
+			intersection: "{5-10}",
+			difference:   "{1-4}",
+		},
+		{
+			name:         "hole in the middle",
+			a:            []Interval[int64]{iv(1, 10)},
+			b:            []Interval[int64]{iv(3, 4), iv(7, 7)},
+			union:        "{1-10}",
+			intersection: "{3-4, 7-7}",
+			difference:   "{1-2, 5-6, 8-10}",
+		},
+		{
+			name:         "covered",
+			a:            []Interval[int64]{iv(3, 4), iv(8, 9)},
+			b:            []Interval[int64]{iv(1, 10)},
+			union:        "{1-10}",
+			intersection: "{3-4, 8-9}",
+			difference:   "{}",
+		},
+		{
+			name:         "spanning several",
+			a:            []Interval[int64]{iv(0, 4), iv(6, 10), iv(12, 20)},
+			b:            []Interval[int64]{iv(3, 7), iv(9, 13)},
+			union:        "{0-20}",
+			intersection: "{3-4, 6-7, 9-10, 12-13}",
+			difference:   "{0-2, 8-8, 14-20}",
+		},
+		{
+			name:         "up to the largest int64",
+			a:            []Interval[int64]{iv(math.MaxInt64-3, math.MaxInt64)},
+			b:            []Interval[int64]{iv(math.MaxInt64-1, math.MaxInt64)},
+			union:        fmt.Sprintf("{%d-%d}", int64(math.MaxInt64-3), int64(math.MaxInt64)),
+			intersection: fmt.Sprintf("{%d-%d}", int64(math.MaxInt64-1), int64(math.MaxInt64)),
+			difference:   fmt.Sprintf("{%d-%d}", int64(math.MaxInt64-3), int64(math.MaxInt64-2)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := New(tt.a...), New(tt.b...)
+			if got := a.Union(b).String(); got != tt.union {
+				t.Errorf("Union() = %s, want %s", got, tt.union)
+			}
+			if got := b.Union(a).String(); got != tt.union {
+				t.Errorf("Union() the other way = %s, want %s", got, tt.union)
+			}
+			if got := a.Intersect(b).String(); got != tt.intersection {
+				t.Errorf("Intersect() = %s, want %s", got, tt.intersection)
+			}
+			if got := b.Intersect(a).String(); got != tt.intersection {
+				t.Errorf("Intersect() the other way = %s, want %s", got, tt.intersection)
+			}
+			if got := a.Difference(b).String(); got != tt.difference {
+				t.Errorf("Difference() = %s, want %s", got, tt.difference)
+			}
+
+			bigA, bigB := bigSet(a), bigSet(b)
+			if got := bigA.Union(bigB).String(); got != tt.union {
+				t.Errorf("big Union() = %s, want %s", got, tt.union)
+			}
+			if got := bigA.Intersect(bigB).String(); got != tt.intersection {
+				t.Errorf("big Intersect() = %s, want %s", got, tt.intersection)
+			}
+			if got := bigA.Difference(bigB).String(); got != tt.difference {
+				t.Errorf("big Difference() = %s, want %s", got, tt.difference)
+			}
+		})
+	}
+}
+
+func TestComplement(t *testing.T) {
+	s := New(iv(3, 5), iv(10, 20))
+	tests := []struct {
+		bounds Interval[int64]
+		want   string
+	}{
+		{iv(0, 30), "{0-2, 6-9, 21-30}"},
+		{iv(3, 20), "{6-9}"},
+		{iv(11, 19), "{}"},
+		{iv(8, 7), "{}"},
+	}
+	for _, tt := range tests {
+		if got := s.Complement(tt.bounds).String(); got != tt.want {
+			t.Errorf("Complement(%v) = %s, want %s", tt.bounds, got, tt.want)
+		}
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name   string
+		insert Interval[int64]
+		want   string
+	}{
+		{"empty interval", iv(9, 8), "{3-5, 10-20}"},
+		{"apart", iv(7, 8), "{3-5, 7-8, 10-20}"},
+		{"touching both", iv(6, 9), "{3-20}"},
+		{"touching the end", iv(21, 25), "{3-5, 10-25}"},
+		{"covering everything", iv(0, 30), "{0-30}"},
+		{"inside", iv(12, 13), "{3-5, 10-20}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(iv(3, 5), iv(10, 20))
+			s.Insert(tt.insert)
+			if got := s.String(); got != tt.want {
+				t.Errorf("Insert(%v) = %s, want %s", tt.insert, got, tt.want)
+			}
+		})
+	}
+}
+
+// Sets of big integers keep their own endpoints, so callers may reuse theirs.
+func TestBigEndpointsCopied(t *testing.T) {
+	lo, hi := big.NewInt(10), big.NewInt(20)
+	s := NewBig(Interval[*big.Int]{lo, hi})
+	lo.SetInt64(100)
+	hi.SetInt64(200)
+
+	lo.SetInt64(30)
+	hi.SetInt64(40)
+	s.Insert(Interval[*big.Int]{lo, hi})
+	lo.SetInt64(-5)
+	hi.SetInt64(-1)
+
+	if got, want := s.String(), "{10-20, 30-40}"; got != want {
+		t.Errorf("set = %s, want %s", got, want)
+	}
+}
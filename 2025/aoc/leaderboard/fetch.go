@@ -0,0 +1,67 @@
+package leaderboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is where leaderboards are fetched from.
+const DefaultBaseURL = "https://adventofcode.com"
+
+// userAgent identifies the requests, as adventofcode.com asks of automated tools.
+const userAgent = "aoc leaderboard"
+
+// Fetcher downloads private leaderboards. adventofcode.com asks that a leaderboard is
+// fetched at most once every 15 minutes.
+type Fetcher struct {
+	// BaseURL is the server to fetch from; DefaultBaseURL if empty. A local stand-in
+	// serving the same paths can be used instead.
+	BaseURL string
+	// Session is the value of the session cookie of a logged-in member of the leaderboard.
+	Session string
+	// Client makes the requests; http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Fetch downloads the leaderboard with an ID for the event of a year.
+func (f *Fetcher) Fetch(ctx context.Context, year, id int) (*Leaderboard, error) {
+	base := f.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	address, err := url.JoinPath(base, fmt.Sprint(year), "leaderboard", "private", "view", fmt.Sprintf("%d.json", id))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if f.Session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: f.Session})
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", address, resp.Status)
+	}
+	// Without a valid session the server redirects to the login page, which is HTML
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+		return nil, fmt.Errorf("%s: got %s instead of JSON; is the session cookie valid?", address, ct)
+	}
+	return Parse(resp.Body, address)
+}
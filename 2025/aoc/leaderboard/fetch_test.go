@@ -0,0 +1,163 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// export is a leaderboard of three members over two days. Day 1 unlocked at 1764565200
+// and day 2 at 1764651600. The anonymous member's local score is stale.
+const export = `{
+  "event": "2025",
+  "owner_id": 1,
+  "members": {
+    "1": {"id": 1, "name": "Alice", "stars": 2, "local_score": 5, "last_star_ts": 1764567000,
+      "completion_day_level": {
+        "1": {"1": {"get_star_ts": 1764565800, "star_index": 10}, "2": {"get_star_ts": 1764567000, "star_index": 30}}
+      }},
+    "2": {"id": 2, "name": "Bob", "stars": 4, "local_score": 10, "last_star_ts": 1764655800,
+      "completion_day_level": {
+        "1": {"1": {"get_star_ts": 1764565500, "star_index": 5}, "2": {"get_star_ts": 1764568200, "star_index": 50}},
+        "2": {"1": {"get_star_ts": 1764655200, "star_index": 120}, "2": {"get_star_ts": 1764655800, "star_index": 130}}
+      }},
+    "3": {"id": 3, "name": "", "stars": 3, "local_score": 7, "last_star_ts": 1764658860,
+      "completion_day_level": {
+        "1": {"1": {"get_star_ts": 1764566400, "star_index": 20}},
+        "2": {"1": {"get_star_ts": 1764653400, "star_index": 110}, "2": {"get_star_ts": 1764658860, "star_index": 140}}
+      }}
+  }
+}`
+
+// serve starts a server answering requests for leaderboard 42 of 2025 with handler.
+func serve(t *testing.T, handler http.HandlerFunc) *Fetcher {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /2025/leaderboard/private/view/42.json", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return &Fetcher{BaseURL: server.URL, Session: "53551", Client: server.Client()}
+}
+
+func TestFetch(t *testing.T) {
+	fetcher := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "53551" {
+			t.Errorf("session cookie = %v, %v; want 53551", cookie, err)
+		}
+		if agent := r.UserAgent(); agent != userAgent {
+			t.Errorf("User-Agent = %q, want %q", agent, userAgent)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(export))
+	})
+
+	l, err := fetcher.Fetch(context.Background(), 2025, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Event != "2025" || l.OwnerID != 1 || len(l.Members) != 3 {
+		t.Errorf("got event %q, owner %d and %d members", l.Event, l.OwnerID, len(l.Members))
+	}
+	star, ok := l.Members["2"].Star(2, 1)
+	if !ok || star.Time != 1764655200 || star.Index != 120 {
+		t.Errorf("member 2 day 2 part 1 = %+v, %v", star, ok)
+	}
+	if name := l.Members["3"].DisplayName(); name != "(anonymous user #3)" {
+		t.Errorf("DisplayName() = %q", name)
+	}
+}
+
+func TestFetchWithoutSession(t *testing.T) {
+	fetcher := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err == nil {
+			t.Error("a session cookie was sent without a session")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(export))
+	})
+	fetcher.Session = ""
+	if _, err := fetcher.Fetch(context.Background(), 2025, 42); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "not found", http.StatusNotFound)
+			},
+			want: "404 Not Found",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "oops", http.StatusInternalServerError)
+			},
+			want: "500 Internal Server Error",
+		},
+		{
+			name: "login page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write([]byte("<html>Log in</html>"))
+			},
+			want: "is the session cookie valid?",
+		},
+		{
+			name: "malformed JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"event": "2025", "members": {`))
+			},
+			want: "unexpected EOF",
+		},
+		{
+			name: "invalid event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"event": "twenty", "members": {}}`))
+			},
+			want: `invalid event "twenty"`,
+		},
+		{
+			name: "mismatched member ID",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"event": "2025", "members": {"1": {"id": 2}}}`))
+			},
+			want: `member "1" has ID 2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := serve(t, tt.handler)
+			l, err := fetcher.Fetch(context.Background(), 2025, 42)
+			if err == nil {
+				t.Fatalf("Fetch() = %+v, want an error", l)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Fetch() error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchCanceled(t *testing.T) {
+	fetcher := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(export))
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := fetcher.Fetch(ctx, 2025, 42); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,138 @@
+// Package leaderboard reads the JSON export of a private leaderboard and works out
+// what it says about each member: when they got their stars, how long part 2 took
+// them, how the standings moved from day to day and what their local score is.
+//
+// The export is what adventofcode.com serves at
+//
+//	/{year}/leaderboard/private/view/{id}.json
+//
+// and is read from a file with Read or fetched with a Fetcher.
+package leaderboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Parts is the number of parts of a puzzle.
+const Parts = 2
+
+// Leaderboard is a private leaderboard as exported by adventofcode.com.
+type Leaderboard struct {
+	Event   string             `json:"event"`
+	OwnerID int                `json:"owner_id"`
+	Members map[string]*Member `json:"members"`
+}
+
+// Member is a member of a leaderboard and the stars they have.
+type Member struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"` // empty for anonymous users
+	Stars      int    `json:"stars"`
+	LocalScore int    `json:"local_score"`
+	LastStar   int64  `json:"last_star_ts"`
+
+	// Completion holds the stars by day and then by part, both written as strings.
+	Completion map[string]map[string]Star `json:"completion_day_level"`
+}
+
+// Star is when a member got the star of one part of a day.
+type Star struct {
+	Time  int64 `json:"get_star_ts"` // Unix time
+	Index int   `json:"star_index"`  // orders stars got in the same second
+}
+
+// DisplayName is the member's name, or how adventofcode.com shows an anonymous user.
+func (m *Member) DisplayName() string {
+	if m.Name == "" {
+		return fmt.Sprintf("(anonymous user #%d)", m.ID)
+	}
+	return m.Name
+}
+
+// Star returns the star of a part of a day, if the member has it.
+func (m *Member) Star(day, part int) (Star, bool) {
+	star, ok := m.Completion[strconv.Itoa(day)][strconv.Itoa(part)]
+	return star, ok
+}
+
+// Year is the year of the event.
+func (l *Leaderboard) Year() (int, error) {
+	year, err := strconv.Atoi(l.Event)
+	if err != nil {
+		return 0, fmt.Errorf("invalid event %q", l.Event)
+	}
+	return year, nil
+}
+
+// Days returns the days, from 1, up to the last day any member has a star for.
+func (l *Leaderboard) Days() int {
+	days := 0
+	for _, m := range l.Members {
+		for key := range m.Completion {
+			if day, err := strconv.Atoi(key); err == nil && day > days {
+				days = day
+			}
+		}
+	}
+	return days
+}
+
+// SortedMembers returns the members ordered by ID.
+func (l *Leaderboard) SortedMembers() []*Member {
+	members := slices.Collect(maps.Values(l.Members))
+	slices.SortFunc(members, func(a, b *Member) int { return a.ID - b.ID })
+	return members
+}
+
+// Unlock returns when the puzzle of a day of an event was released: midnight in
+// the US Eastern time zone, which is UTC-5 in December.
+func Unlock(year, day int) time.Time {
+	return time.Date(year, time.December, day, 5, 0, 0, 0, time.UTC)
+}
+
+// Parse decodes a leaderboard export. name is used in errors.
+func Parse(r io.Reader, name string) (*Leaderboard, error) {
+	var l Leaderboard
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if _, err := l.Year(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	for key, m := range l.Members {
+		if id, err := strconv.Atoi(key); err != nil || id != m.ID {
+			return nil, fmt.Errorf("%s: member %q has ID %d", name, key, m.ID)
+		}
+		for day, parts := range m.Completion {
+			if n, err := strconv.Atoi(day); err != nil || n < 1 || n > 25 {
+				return nil, fmt.Errorf("%s: member %d: invalid day %q", name, m.ID, day)
+			}
+			for part := range parts {
+				if n, err := strconv.Atoi(part); err != nil || n < 1 || n > Parts {
+					return nil, fmt.Errorf("%s: member %d: day %s: invalid part %q", name, m.ID, day, part)
+				}
+			}
+		}
+	}
+	return &l, nil
+}
+
+// Read decodes the leaderboard export in a file, or in standard input for "-".
+func Read(filename string) (*Leaderboard, error) {
+	if filename == "-" {
+		return Parse(os.Stdin, "standard input")
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, filename)
+}
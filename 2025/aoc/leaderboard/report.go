@@ -0,0 +1,188 @@
+package leaderboard
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Report is what a leaderboard says about its members, day by day.
+type Report struct {
+	Year      int         `json:"year"`
+	Members   int         `json:"members"`
+	Days      []DayReport `json:"days"`
+	Standings []Standing  `json:"standings"`
+}
+
+// DayReport holds the results of every member on a day, ordered by their rank after it.
+type DayReport struct {
+	Day     int      `json:"day"`
+	Results []Result `json:"results"`
+}
+
+// Result is how a member did on a day. The times are counted from the unlock of the
+// puzzle and are nil for a star the member does not have.
+type Result struct {
+	ID    int       `json:"id"`
+	Name  string    `json:"name"`
+	Part1 *Duration `json:"part1,omitempty"`
+	Part2 *Duration `json:"part2,omitempty"`
+	Delta *Duration `json:"part2_delta,omitempty"` // from the first star to the second
+
+	Points     int `json:"points"`      // local score earned on the day
+	Score      int `json:"score"`       // local score after the day
+	Rank       int `json:"rank"`        // rank after the day
+	RankChange int `json:"rank_change"` // places gained since the day before
+}
+
+// Standing is a member's place after the last day.
+type Standing struct {
+	Rank  int    `json:"rank"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Stars int    `json:"stars"`
+	Score int    `json:"score"`
+	// ReportedScore is the local score in the export, which differs from Score when
+	// the export is stale or the event scored some days differently.
+	ReportedScore int `json:"reported_score"`
+}
+
+// Duration is a time since a puzzle unlocked. It is written in JSON as whole seconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(time.Duration(d)/time.Second), 10), nil
+}
+
+// String formats the duration as hours, minutes and seconds, like 26:04:05.
+func (d Duration) String() string {
+	seconds := int64(time.Duration(d) / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// Analyze works out the results of every member on every day up to the last one with
+// a star. The local score is recomputed the way adventofcode.com does it: on each
+// part, the first member to get the star earns as many points as the leaderboard has
+// members, the second one point less, and so on.
+func Analyze(l *Leaderboard) (*Report, error) {
+	year, err := l.Year()
+	if err != nil {
+		return nil, err
+	}
+	members := l.SortedMembers()
+	report := &Report{Year: year, Members: len(members)}
+
+	scores := make(map[int]int, len(members))
+	lastStars := make(map[int]Star, len(members))
+	previousRanks := map[int]int{}
+	for day := 1; day <= l.Days(); day++ {
+		points := map[int]int{}
+		for part := 1; part <= Parts; part++ {
+			var got []*Member
+			for _, m := range members {
+				if _, ok := m.Star(day, part); ok {
+					got = append(got, m)
+				}
+			}
+			slices.SortStableFunc(got, func(a, b *Member) int {
+				starA, _ := a.Star(day, part)
+				starB, _ := b.Star(day, part)
+				return compareStars(starA, starB)
+			})
+			for i, m := range got {
+				points[m.ID] += len(members) - i
+				if star, _ := m.Star(day, part); compareStars(star, lastStars[m.ID]) > 0 {
+					lastStars[m.ID] = star
+				}
+			}
+		}
+		for id, p := range points {
+			scores[id] += p
+		}
+
+		ranked := rank(members, scores, lastStars)
+		dayReport := DayReport{Day: day}
+		for i, m := range ranked {
+			result := Result{
+				ID:     m.ID,
+				Name:   m.DisplayName(),
+				Points: points[m.ID],
+				Score:  scores[m.ID],
+				Rank:   i + 1,
+			}
+			if previous, ok := previousRanks[m.ID]; ok {
+				result.RankChange = previous - result.Rank
+			}
+			previousRanks[m.ID] = result.Rank
+
+			unlock := Unlock(year, day)
+			first, ok1 := m.Star(day, 1)
+			second, ok2 := m.Star(day, 2)
+			if ok1 {
+				result.Part1 = since(unlock, first)
+			}
+			if ok2 {
+				result.Part2 = since(unlock, second)
+			}
+			if ok1 && ok2 {
+				delta := *result.Part2 - *result.Part1
+				result.Delta = &delta
+			}
+			dayReport.Results = append(dayReport.Results, result)
+		}
+		report.Days = append(report.Days, dayReport)
+	}
+
+	for i, m := range rank(members, scores, lastStars) {
+		stars := 0
+		for _, parts := range m.Completion {
+			stars += len(parts)
+		}
+		report.Standings = append(report.Standings, Standing{
+			Rank:          i + 1,
+			ID:            m.ID,
+			Name:          m.DisplayName(),
+			Stars:         stars,
+			Score:         scores[m.ID],
+			ReportedScore: m.LocalScore,
+		})
+	}
+	return report, nil
+}
+
+// rank orders the members by score. Members with the same score are ordered by who
+// got their last star first, as on the leaderboard page, and then by ID.
+func rank(members []*Member, scores map[int]int, lastStars map[int]Star) []*Member {
+	ranked := slices.Clone(members)
+	slices.SortStableFunc(ranked, func(a, b *Member) int {
+		if c := cmp.Compare(scores[b.ID], scores[a.ID]); c != 0 {
+			return c
+		}
+		starA, okA := lastStars[a.ID]
+		starB, okB := lastStars[b.ID]
+		switch {
+		case okA && okB:
+			if c := compareStars(starA, starB); c != 0 {
+				return c
+			}
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return ranked
+}
+
+// compareStars orders stars by when they were got.
+func compareStars(a, b Star) int {
+	return cmp.Or(cmp.Compare(a.Time, b.Time), cmp.Compare(a.Index, b.Index))
+}
+
+func since(unlock time.Time, star Star) *Duration {
+	d := Duration(time.Unix(star.Time, 0).Sub(unlock))
+	return &d
+}
@@ -0,0 +1,123 @@
+package leaderboard
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func minutes(n int) *Duration {
+	d := Duration(time.Duration(n) * time.Minute)
+	return &d
+}
+
+func TestAnalyze(t *testing.T) {
+	l, err := Parse(strings.NewReader(export), "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Analyze(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Year != 2025 || report.Members != 3 || len(report.Days) != 2 {
+		t.Fatalf("got year %d, %d members and %d days", report.Year, report.Members, len(report.Days))
+	}
+
+	// On day 1 Bob is first to part 1 and Alice to part 2, so they tie on 5 points, and
+	// Alice ranks first for getting her last star earlier. On day 2 the anonymous member
+	// is first to part 1 and overtakes Alice, who has no star that day.
+	want := []DayReport{
+		{Day: 1, Results: []Result{
+			{ID: 1, Name: "Alice", Part1: minutes(10), Part2: minutes(30), Delta: minutes(20), Points: 5, Score: 5, Rank: 1},
+			{ID: 2, Name: "Bob", Part1: minutes(5), Part2: minutes(50), Delta: minutes(45), Points: 5, Score: 5, Rank: 2},
+			{ID: 3, Name: "(anonymous user #3)", Part1: minutes(20), Points: 1, Score: 1, Rank: 3},
+		}},
+		{Day: 2, Results: []Result{
+			{ID: 2, Name: "Bob", Part1: minutes(60), Part2: minutes(70), Delta: minutes(10), Points: 5, Score: 10, Rank: 1, RankChange: 1},
+			{ID: 3, Name: "(anonymous user #3)", Part1: minutes(30), Part2: minutes(121), Delta: minutes(91), Points: 5, Score: 6, Rank: 2, RankChange: 1},
+			{ID: 1, Name: "Alice", Points: 0, Score: 5, Rank: 3, RankChange: -2},
+		}},
+	}
+	for i, day := range report.Days {
+		if day.Day != want[i].Day || len(day.Results) != len(want[i].Results) {
+			t.Fatalf("day %d: got day %d with %d results", want[i].Day, day.Day, len(day.Results))
+		}
+		for j, got := range day.Results {
+			if w := want[i].Results[j]; !sameResult(got, w) {
+				t.Errorf("day %d, result %d:\ngot  %s\nwant %s", day.Day, j+1, formatResult(got), formatResult(w))
+			}
+		}
+	}
+
+	wantStandings := []Standing{
+		{Rank: 1, ID: 2, Name: "Bob", Stars: 4, Score: 10, ReportedScore: 10},
+		{Rank: 2, ID: 3, Name: "(anonymous user #3)", Stars: 3, Score: 6, ReportedScore: 7},
+		{Rank: 3, ID: 1, Name: "Alice", Stars: 2, Score: 5, ReportedScore: 5},
+	}
+	if len(report.Standings) != len(wantStandings) {
+		t.Fatalf("got %d standings, want %d", len(report.Standings), len(wantStandings))
+	}
+	for i, got := range report.Standings {
+		if got != wantStandings[i] {
+			t.Errorf("standing %d = %+v, want %+v", i+1, got, wantStandings[i])
+		}
+	}
+}
+
+func TestAnalyzeSameSecond(t *testing.T) {
+	// Both members got each star in the same second, so the star index decides who
+	// was first: Bob on part 1 and Alice on part 2.
+	l := &Leaderboard{Event: "2025", Members: map[string]*Member{
+		"1": {ID: 1, Name: "Alice", Completion: map[string]map[string]Star{
+			"3": {"1": {Time: 1764738000, Index: 2}, "2": {Time: 1764738060, Index: 3}},
+		}},
+		"2": {ID: 2, Name: "Bob", Completion: map[string]map[string]Star{
+			"3": {"1": {Time: 1764738000, Index: 1}, "2": {Time: 1764738060, Index: 4}},
+		}},
+	}}
+	report, err := Analyze(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Days) != 3 {
+		t.Fatalf("got %d days, want 3", len(report.Days))
+	}
+	for _, result := range report.Days[0].Results {
+		if result.Points != 0 || result.Part1 != nil {
+			t.Errorf("day 1 result without stars = %s", formatResult(result))
+		}
+	}
+	day := report.Days[2]
+	if got := day.Results[0]; got.ID != 1 || got.Points != 3 || got.Rank != 1 {
+		t.Errorf("first on day 3 = %s, want Alice with 3 points", formatResult(got))
+	}
+	if got := day.Results[1]; got.ID != 2 || got.Points != 3 || got.Rank != 2 {
+		t.Errorf("second on day 3 = %s, want Bob with 3 points", formatResult(got))
+	}
+}
+
+func TestAnalyzeInvalidEvent(t *testing.T) {
+	if _, err := Analyze(&Leaderboard{Event: "next year"}); err == nil {
+		t.Error("Analyze() succeeded on an invalid event")
+	}
+}
+
+func sameResult(a, b Result) bool {
+	same := func(x, y *Duration) bool { return x == nil && y == nil || x != nil && y != nil && *x == *y }
+	return a.ID == b.ID && a.Name == b.Name && a.Points == b.Points && a.Score == b.Score &&
+		a.Rank == b.Rank && a.RankChange == b.RankChange &&
+		same(a.Part1, b.Part1) && same(a.Part2, b.Part2) && same(a.Delta, b.Delta)
+}
+
+func formatResult(r Result) string {
+	format := func(d *Duration) string {
+		if d == nil {
+			return "-"
+		}
+		return d.String()
+	}
+	return fmt.Sprintf("%s %s %s delta %s, %d points, score %d, rank %d, change %d",
+		r.Name, format(r.Part1), format(r.Part2), format(r.Delta), r.Points, r.Score, r.Rank, r.RankChange)
+}
@@ -0,0 +1,87 @@
+package linalg
+
+import (
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// field describes the arithmetic of matrix entries. Values returned by its methods
+// are never shared with their arguments, so entries can be stored without copying.
+type field[E any] interface {
+	zero() E
+	fromInt(x int64) E
+	clone(a E) E
+	isZero(a E) bool
+	add(a, b E) E
+	sub(a, b E) E
+	mul(a, b E) E
+	// div returns a/b for a non-zero b.
+	div(a, b E) E
+	format(a E) string
+}
+
+// rationals is the field of rational numbers backed by math/big.
+type rationals struct{}
+
+func (rationals) zero() *big.Rat             { return new(big.Rat) }
+func (rationals) fromInt(x int64) *big.Rat   { return new(big.Rat).SetInt64(x) }
+func (rationals) clone(a *big.Rat) *big.Rat  { return new(big.Rat).Set(a) }
+func (rationals) isZero(a *big.Rat) bool     { return a.Sign() == 0 }
+func (rationals) add(a, b *big.Rat) *big.Rat { return new(big.Rat).Add(a, b) }
+func (rationals) sub(a, b *big.Rat) *big.Rat { return new(big.Rat).Sub(a, b) }
+func (rationals) mul(a, b *big.Rat) *big.Rat { return new(big.Rat).Mul(a, b) }
+func (rationals) div(a, b *big.Rat) *big.Rat { return new(big.Rat).Quo(a, b) }
+func (rationals) format(a *big.Rat) string   { return a.RatString() }
+
+// integersModulo is the field of integers modulo a prime p < 2^63.
+type integersModulo struct {
+	p uint64
+}
+
+func (f integersModulo) zero() uint64 { return 0 }
+
+func (f integersModulo) fromInt(x int64) uint64 {
+	r := x % int64(f.p)
+	if r < 0 {
+		r += int64(f.p)
+	}
+	return uint64(r)
+}
+
+func (f integersModulo) clone(a uint64) uint64 { return a % f.p }
+
+func (f integersModulo) isZero(a uint64) bool { return a == 0 }
+
+func (f integersModulo) add(a, b uint64) uint64 {
+	// p < 2^63, so the sum cannot overflow
+	return (a + b) % f.p
+}
+
+func (f integersModulo) sub(a, b uint64) uint64 {
+	return (a + f.p - b) % f.p
+}
+
+func (f integersModulo) mul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, rem := bits.Div64(hi%f.p, lo, f.p)
+	return rem
+}
+
+func (f integersModulo) div(a, b uint64) uint64 {
+	return f.mul(a, f.inverse(b))
+}
+
+// inverse uses Fermat's little theorem: b^(p-2) is the inverse of b modulo a prime p.
+func (f integersModulo) inverse(b uint64) uint64 {
+	result, base := uint64(1), b%f.p
+	for e := f.p - 2; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			result = f.mul(result, base)
+		}
+		base = f.mul(base, base)
+	}
+	return result
+}
+
+func (f integersModulo) format(a uint64) string { return strconv.FormatUint(a, 10) }
@@ -0,0 +1,215 @@
+package linalg
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// BitVector is a vector over GF(2) packed 64 entries per word.
+type BitVector []uint64
+
+// NewBitVector creates a zero vector of length n.
+func NewBitVector(n int) BitVector {
+	return make(BitVector, (n+63)/64)
+}
+
+// Get returns entry i.
+func (v BitVector) Get(i int) bool {
+	return v[i/64]&(1<<(i%64)) != 0
+}
+
+// Set stores entry i.
+func (v BitVector) Set(i int, bit bool) {
+	if bit {
+		v[i/64] |= 1 << (i % 64)
+	} else {
+		v[i/64] &^= 1 << (i % 64)
+	}
+}
+
+// Xor adds w to v in place.
+func (v BitVector) Xor(w BitVector) {
+	for i := range v {
+		v[i] ^= w[i]
+	}
+}
+
+// OnesCount returns the number of entries set to 1.
+func (v BitVector) OnesCount() int {
+	count := 0
+	for _, word := range v {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// GF2Matrix is a matrix over GF(2) with rows stored as bit vectors, so row
+// operations work on 64 columns at a time.
+type GF2Matrix struct {
+	rows int
+	cols int
+	data []BitVector
+}
+
+// NewGF2 creates a rows x cols zero matrix over GF(2).
+func NewGF2(rows, cols int) *GF2Matrix {
+	data := make([]BitVector, rows)
+	for i := range data {
+		data[i] = NewBitVector(cols)
+	}
+	return &GF2Matrix{rows: rows, cols: cols, data: data}
+}
+
+// Rows returns the number of rows.
+func (m *GF2Matrix) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns.
+func (m *GF2Matrix) Cols() int {
+	return m.cols
+}
+
+// At returns the entry in row i and column j.
+func (m *GF2Matrix) At(i, j int) bool {
+	return m.data[i].Get(j)
+}
+
+// Set stores the entry in row i and column j.
+func (m *GF2Matrix) Set(i, j int, bit bool) {
+	m.data[i].Set(j, bit)
+}
+
+// Clone returns a deep copy of the matrix.
+func (m *GF2Matrix) Clone() *GF2Matrix {
+	c := NewGF2(m.rows, m.cols)
+	for i, row := range m.data {
+		copy(c.data[i], row)
+	}
+	return c
+}
+
+// RREF transforms the matrix in place into reduced row echelon form and returns
+// the pivot column of each non-zero row.
+func (m *GF2Matrix) RREF() []int {
+	return m.rref(m.cols)
+}
+
+func (m *GF2Matrix) rref(limit int) []int {
+	var pivots []int
+	pivotRow := 0
+	for col := 0; col < limit && pivotRow < m.rows; col++ {
+		row := pivotRow
+		for row < m.rows && !m.data[row].Get(col) {
+			row++
+		}
+		if row == m.rows {
+			continue
+		}
+		m.data[pivotRow], m.data[row] = m.data[row], m.data[pivotRow]
+
+		// Over GF(2) the pivot is already 1, and elimination is a XOR
+		for r := 0; r < m.rows; r++ {
+			if r != pivotRow && m.data[r].Get(col) {
+				m.data[r].Xor(m.data[pivotRow])
+			}
+		}
+
+		pivots = append(pivots, col)
+		pivotRow++
+	}
+	return pivots
+}
+
+// Rank returns the rank of the matrix.
+func (m *GF2Matrix) Rank() int {
+	return len(m.Clone().RREF())
+}
+
+// NullSpace returns a basis of the vectors x with m·x = 0.
+func (m *GF2Matrix) NullSpace() []BitVector {
+	reduced := m.Clone()
+	pivots := reduced.RREF()
+	return reduced.nullSpace(pivots, m.cols)
+}
+
+func (m *GF2Matrix) nullSpace(pivots []int, variables int) []BitVector {
+	var basis []BitVector
+	for _, free := range freeColumns(pivots, variables) {
+		v := NewBitVector(variables)
+		v.Set(free, true)
+		for row, pivot := range pivots {
+			v.Set(pivot, m.data[row].Get(free))
+		}
+		basis = append(basis, v)
+	}
+	return basis
+}
+
+// GF2Solution describes every solution of a GF(2) system as Particular xor any
+// combination of the Basis vectors. Basis[k] is the direction of free variable Free[k].
+type GF2Solution struct {
+	Particular BitVector
+	Free       []int
+	Basis      []BitVector
+}
+
+// At returns the solution obtained by setting the free variables whose bits are set in mask.
+// It supports up to 64 free variables.
+func (s *GF2Solution) At(mask uint64) BitVector {
+	x := make(BitVector, len(s.Particular))
+	copy(x, s.Particular)
+	for k, direction := range s.Basis {
+		if mask&(1<<k) != 0 {
+			x.Xor(direction)
+		}
+	}
+	return x
+}
+
+// Solve returns the solution set of m·x = b, where b has one entry per row,
+// or ErrInconsistent if there is none.
+func (m *GF2Matrix) Solve(b BitVector) (*GF2Solution, error) {
+	augmented := NewGF2(m.rows, m.cols+1)
+	for i, row := range m.data {
+		copy(augmented.data[i], row)
+		augmented.data[i].Set(m.cols, b.Get(i))
+	}
+	pivots := augmented.rref(m.cols)
+
+	for row := len(pivots); row < m.rows; row++ {
+		if augmented.data[row].Get(m.cols) {
+			return nil, ErrInconsistent
+		}
+	}
+
+	particular := NewBitVector(m.cols)
+	for row, pivot := range pivots {
+		particular.Set(pivot, augmented.data[row].Get(m.cols))
+	}
+
+	return &GF2Solution{
+		Particular: particular,
+		Free:       freeColumns(pivots, m.cols),
+		Basis:      augmented.nullSpace(pivots, m.cols),
+	}, nil
+}
+
+// String formats the matrix one row per line, using 0 and 1.
+func (m *GF2Matrix) String() string {
+	var sb strings.Builder
+	for _, row := range m.data {
+		for j := 0; j < m.cols; j++ {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			if row.Get(j) {
+				sb.WriteByte('1')
+			} else {
+				sb.WriteByte('0')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
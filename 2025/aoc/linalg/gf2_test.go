@@ -0,0 +1,185 @@
+package linalg
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gf2 creates a matrix over GF(2) from rows written as strings of 0s and 1s.
+func gf2(rows ...string) *GF2Matrix {
+	m := NewGF2(len(rows), len(rows[0]))
+	for i, row := range rows {
+		for j, c := range row {
+			m.Set(i, j, c == '1')
+		}
+	}
+	return m
+}
+
+// bitVector creates a vector from a string of 0s and 1s.
+func bitVector(s string) BitVector {
+	v := NewBitVector(len(s))
+	for i, c := range s {
+		v.Set(i, c == '1')
+	}
+	return v
+}
+
+// formatBits writes the first n entries of a vector as a string of 0s and 1s.
+func formatBits(v BitVector, n int) string {
+	var sb strings.Builder
+	for i := range n {
+		if v.Get(i) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// unitVectors returns the vectors of length n with a single 1 in each column from
+// first up to but not including last.
+func unitVectors(n, first, last int) []string {
+	var vectors []string
+	for col := first; col < last; col++ {
+		vectors = append(vectors, strings.Repeat("0", col)+"1"+strings.Repeat("0", n-col-1))
+	}
+	return vectors
+}
+
+func TestGF2Reduction(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   []string
+		rref   string
+		pivots []int
+		null   []string
+	}{
+		{
+			name:   "identity",
+			rows:   []string{"10", "01"},
+			rref:   "1 0\n0 1\n",
+			pivots: []int{0, 1},
+		},
+		{
+			name:   "dependent row",
+			rows:   []string{"110", "011", "101"},
+			rref:   "1 0 1\n0 1 1\n0 0 0\n",
+			pivots: []int{0, 1},
+			null:   []string{"111"},
+		},
+		{
+			name: "zero matrix",
+			rows: []string{"00", "00"},
+			rref: "0 0\n0 0\n",
+			null: []string{"10", "01"},
+		},
+		{
+			// Columns past the first word are reduced too
+			name:   "wide",
+			rows:   []string{"1" + strings.Repeat("0", 69) + "1", strings.Repeat("0", 70) + "1"},
+			rref:   "1" + strings.Repeat(" 0", 70) + "\n" + strings.Repeat("0 ", 70) + "1\n",
+			pivots: []int{0, 70},
+			null:   unitVectors(71, 1, 70),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := gf2(tt.rows...)
+			if got := m.Rank(); got != len(tt.pivots) {
+				t.Errorf("Rank() = %d, want %d", got, len(tt.pivots))
+			}
+			var null []string
+			for _, v := range m.NullSpace() {
+				null = append(null, formatBits(v, m.Cols()))
+			}
+			if !slices.Equal(null, tt.null) {
+				t.Errorf("NullSpace() = %q, want %q", null, tt.null)
+			}
+			if got := m.RREF(); !slices.Equal(got, tt.pivots) {
+				t.Errorf("RREF() pivots = %v, want %v", got, tt.pivots)
+			}
+			if got := m.String(); got != tt.rref {
+				t.Errorf("RREF() =\n%swant\n%s", got, tt.rref)
+			}
+		})
+	}
+}
+
+func TestGF2Solve(t *testing.T) {
+	tests := []struct {
+		name         string
+		rows         []string
+		b            string
+		inconsistent bool
+		particular   string
+		free         []int
+		basis        []string
+	}{
+		{
+			name:       "unique",
+			rows:       []string{"11", "01"},
+			b:          "10",
+			particular: "10",
+		},
+		{
+			name:       "underdetermined",
+			rows:       []string{"110", "011", "101"},
+			b:          "110",
+			particular: "010",
+			free:       []int{2},
+			basis:      []string{"111"},
+		},
+		{
+			name:         "inconsistent",
+			rows:         []string{"110", "011", "101"},
+			b:            "111",
+			inconsistent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := gf2(tt.rows...)
+			s, err := m.Solve(bitVector(tt.b))
+			if tt.inconsistent {
+				if !errors.Is(err, ErrInconsistent) {
+					t.Fatalf("Solve() error = %v, want %v", err, ErrInconsistent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+			if got := formatBits(s.Particular, m.Cols()); got != tt.particular {
+				t.Errorf("Particular = %s, want %s", got, tt.particular)
+			}
+			if !slices.Equal(s.Free, tt.free) {
+				t.Errorf("Free = %v, want %v", s.Free, tt.free)
+			}
+			var basis []string
+			for _, v := range s.Basis {
+				basis = append(basis, formatBits(v, m.Cols()))
+			}
+			if !slices.Equal(basis, tt.basis) {
+				t.Errorf("Basis = %q, want %q", basis, tt.basis)
+			}
+
+			// Every combination of the free variables solves the system
+			for mask := range uint64(1) << len(s.Free) {
+				x := s.At(mask)
+				for i := range m.Rows() {
+					sum := false
+					for j := range m.Cols() {
+						sum = sum != (m.At(i, j) && x.Get(j))
+					}
+					if sum != (tt.b[i] == '1') {
+						t.Errorf("row %d of m·x is wrong at x = %s", i, formatBits(x, m.Cols()))
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+// Package linalg provides exact linear algebra over rational numbers, prime fields and GF(2).
+//
+// Rational matrices (NewRat) use math/big, so elimination never overflows.
+// Modular matrices (NewMod) work in the integers modulo a prime, and GF2Matrix
+// packs GF(2) entries into machine words.
+package linalg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ErrInconsistent is returned when a linear system has no solution.
+var ErrInconsistent = errors.New("linear system is inconsistent")
+
+// Matrix is a dense matrix whose entries belong to an exact field.
+type Matrix[E any] struct {
+	f    field[E]
+	rows int
+	cols int
+	data [][]E
+}
+
+// NewRat creates a rows x cols matrix of rational zeros.
+func NewRat(rows, cols int) *Matrix[*big.Rat] {
+	return newMatrix[*big.Rat](rationals{}, rows, cols)
+}
+
+// NewRatFromInts creates a rational matrix from integer rows of equal length.
+func NewRatFromInts(rows [][]int64) *Matrix[*big.Rat] {
+	return fromInts[*big.Rat](rationals{}, rows)
+}
+
+// NewMod creates a rows x cols matrix of zeros over the integers modulo the prime p.
+// The modulus must be a prime below 2^63; primality is not checked.
+func NewMod(rows, cols int, p uint64) *Matrix[uint64] {
+	if p < 2 || p >= 1<<63 {
+		panic(fmt.Sprintf("linalg: invalid modulus %d", p))
+	}
+	return newMatrix[uint64](integersModulo{p}, rows, cols)
+}
+
+// NewModFromInts creates a matrix over the integers modulo the prime p from integer rows.
+func NewModFromInts(rows [][]int64, p uint64) *Matrix[uint64] {
+	if p < 2 || p >= 1<<63 {
+		panic(fmt.Sprintf("linalg: invalid modulus %d", p))
+	}
+	return fromInts[uint64](integersModulo{p}, rows)
+}
+
+func newMatrix[E any](f field[E], rows, cols int) *Matrix[E] {
+	data := make([][]E, rows)
+	for i := range data {
+		data[i] = make([]E, cols)
+		for j := range data[i] {
+			data[i][j] = f.zero()
+		}
+	}
+	return &Matrix[E]{f: f, rows: rows, cols: cols, data: data}
+}
+
+func fromInts[E any](f field[E], rows [][]int64) *Matrix[E] {
+	cols := 0
+	if len(rows) > 0 {
+		cols = len(rows[0])
+	}
+	m := newMatrix(f, len(rows), cols)
+	for i, row := range rows {
+		if len(row) != cols {
+			panic(fmt.Sprintf("linalg: row %d has %d columns, expected %d", i, len(row), cols))
+		}
+		for j, x := range row {
+			m.data[i][j] = f.fromInt(x)
+		}
+	}
+	return m
+}
+
+// Rows returns the number of rows.
+func (m *Matrix[E]) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns.
+func (m *Matrix[E]) Cols() int {
+	return m.cols
+}
+
+// At returns the entry in row i and column j.
+func (m *Matrix[E]) At(i, j int) E {
+	return m.data[i][j]
+}
+
+// Set stores v in row i and column j.
+func (m *Matrix[E]) Set(i, j int, v E) {
+	m.data[i][j] = m.f.clone(v)
+}
+
+// SetInt stores the integer x in row i and column j.
+func (m *Matrix[E]) SetInt(i, j int, x int64) {
+	m.data[i][j] = m.f.fromInt(x)
+}
+
+// Vector converts integers to a vector over the matrix's field.
+func (m *Matrix[E]) Vector(xs ...int64) []E {
+	v := make([]E, len(xs))
+	for i, x := range xs {
+		v[i] = m.f.fromInt(x)
+	}
+	return v
+}
+
+// Clone returns a deep copy of the matrix.
+func (m *Matrix[E]) Clone() *Matrix[E] {
+	c := newMatrix(m.f, m.rows, m.cols)
+	for i := range m.data {
+		for j := range m.data[i] {
+			c.data[i][j] = m.f.clone(m.data[i][j])
+		}
+	}
+	return c
+}
+
+// RREF transforms the matrix in place into reduced row echelon form and returns
+// the pivot column of each non-zero row.
+func (m *Matrix[E]) RREF() []int {
+	return m.rref(m.cols)
+}
+
+// rref reduces the matrix using only the first limit columns as pivot candidates,
+// which lets augmented matrices keep their right-hand side out of the pivots.
+func (m *Matrix[E]) rref(limit int) []int {
+	var pivots []int
+	pivotRow := 0
+	for col := 0; col < limit && pivotRow < m.rows; col++ {
+		// Find a row with a non-zero entry in this column
+		row := pivotRow
+		for row < m.rows && m.f.isZero(m.data[row][col]) {
+			row++
+		}
+		if row == m.rows {
+			continue // no pivot: this column is a free variable
+		}
+		m.data[pivotRow], m.data[row] = m.data[row], m.data[pivotRow]
+
+		// Scale the pivot row so the pivot becomes 1
+		pivot := m.data[pivotRow][col]
+		for c := col; c < m.cols; c++ {
+			m.data[pivotRow][c] = m.f.div(m.data[pivotRow][c], pivot)
+		}
+
+		// Eliminate the column from every other row
+		for r := 0; r < m.rows; r++ {
+			if r == pivotRow || m.f.isZero(m.data[r][col]) {
+				continue
+			}
+			factor := m.data[r][col]
+			for c := col; c < m.cols; c++ {
+				m.data[r][c] = m.f.sub(m.data[r][c], m.f.mul(factor, m.data[pivotRow][c]))
+			}
+		}
+
+		pivots = append(pivots, col)
+		pivotRow++
+	}
+	return pivots
+}
+
+// Rank returns the rank of the matrix.
+func (m *Matrix[E]) Rank() int {
+	return len(m.Clone().RREF())
+}
+
+// NullSpace returns a basis of the vectors x with m·x = 0.
+// Each basis vector has a 1 in one free column and 0 in the other free columns.
+func (m *Matrix[E]) NullSpace() [][]E {
+	reduced := m.Clone()
+	pivots := reduced.RREF()
+	return reduced.nullSpace(pivots, m.cols)
+}
+
+func (m *Matrix[E]) nullSpace(pivots []int, variables int) [][]E {
+	var basis [][]E
+	for _, free := range freeColumns(pivots, variables) {
+		v := make([]E, variables)
+		for j := range v {
+			v[j] = m.f.zero()
+		}
+		v[free] = m.f.fromInt(1)
+		for row, pivot := range pivots {
+			v[pivot] = m.f.sub(m.f.zero(), m.data[row][free])
+		}
+		basis = append(basis, v)
+	}
+	return basis
+}
+
+func freeColumns(pivots []int, variables int) []int {
+	var free []int
+	next := 0
+	for col := 0; col < variables; col++ {
+		if next < len(pivots) && pivots[next] == col {
+			next++
+			continue
+		}
+		free = append(free, col)
+	}
+	return free
+}
+
+// Solution describes every solution of a linear system A·x = b as
+// Particular + t[0]·Basis[0] + t[1]·Basis[1] + ..., where Basis[k] is the
+// direction of free variable Free[k].
+type Solution[E any] struct {
+	f          field[E]
+	Particular []E   // the solution with every free variable set to 0
+	Free       []int // indices of the free variables
+	Basis      [][]E // null space basis, one vector per free variable
+}
+
+// Solve returns the solution set of m·x = b, or ErrInconsistent if there is none.
+func (m *Matrix[E]) Solve(b []E) (*Solution[E], error) {
+	if len(b) != m.rows {
+		return nil, fmt.Errorf("right-hand side has %d entries, expected %d", len(b), m.rows)
+	}
+
+	augmented := newMatrix(m.f, m.rows, m.cols+1)
+	for i := range m.data {
+		for j := range m.data[i] {
+			augmented.data[i][j] = m.f.clone(m.data[i][j])
+		}
+		augmented.data[i][m.cols] = m.f.clone(b[i])
+	}
+	pivots := augmented.rref(m.cols)
+
+	// A non-zero right-hand side in a zero row means 0 = b
+	for row := len(pivots); row < m.rows; row++ {
+		if !m.f.isZero(augmented.data[row][m.cols]) {
+			return nil, ErrInconsistent
+		}
+	}
+
+	particular := make([]E, m.cols)
+	for j := range particular {
+		particular[j] = m.f.zero()
+	}
+	for row, pivot := range pivots {
+		particular[pivot] = augmented.data[row][m.cols]
+	}
+
+	return &Solution[E]{
+		f:          m.f,
+		Particular: particular,
+		Free:       freeColumns(pivots, m.cols),
+		Basis:      augmented.nullSpace(pivots, m.cols),
+	}, nil
+}
+
+// Unique reports whether the system has exactly one solution.
+func (s *Solution[E]) Unique() bool {
+	return len(s.Free) == 0
+}
+
+// At returns the solution obtained by giving free variable Free[k] the value t[k].
+func (s *Solution[E]) At(t []E) []E {
+	if len(t) != len(s.Free) {
+		panic(fmt.Sprintf("linalg: got %d free values, expected %d", len(t), len(s.Free)))
+	}
+	x := make([]E, len(s.Particular))
+	copy(x, s.Particular)
+	for k, direction := range s.Basis {
+		if s.f.isZero(t[k]) {
+			continue
+		}
+		for j := range x {
+			x[j] = s.f.add(x[j], s.f.mul(t[k], direction[j]))
+		}
+	}
+	return x
+}
+
+// String formats the matrix one row per line.
+func (m *Matrix[E]) String() string {
+	var sb strings.Builder
+	for _, row := range m.data {
+		for j, v := range row {
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(m.f.format(v))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
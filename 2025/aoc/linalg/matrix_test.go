@@ -0,0 +1,264 @@
+package linalg
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// formatVector formats the entries of a vector separated by spaces, like a row of String.
+func formatVector[E any](m *Matrix[E], v []E) string {
+	entries := make([]string, len(v))
+	for i, x := range v {
+		entries[i] = m.f.format(x)
+	}
+	return strings.Join(entries, " ")
+}
+
+func formatVectors[E any](m *Matrix[E], vs [][]E) []string {
+	var formatted []string
+	for _, v := range vs {
+		formatted = append(formatted, formatVector(m, v))
+	}
+	return formatted
+}
+
+// reductionTests are matrices with their reduced row echelon form, pivots, rank and
+// null space basis. Modulus 0 stands for the rationals.
+var reductionTests = []struct {
+	name    string
+	modulus uint64
+	rows    [][]int64
+	rref    string
+	pivots  []int
+	null    []string
+}{
+	{
+		name:   "full rank",
+		rows:   [][]int64{{2, 1}, {1, 3}},
+		rref:   "1 0\n0 1\n",
+		pivots: []int{0, 1},
+	},
+	{
+		name:   "dependent row",
+		rows:   [][]int64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}},
+		rref:   "1 0 1\n0 1 1\n0 0 0\n",
+		pivots: []int{0, 1},
+		null:   []string{"-1 -1 1"},
+	},
+	{
+		name:   "fractions",
+		rows:   [][]int64{{2, 1, 1}},
+		rref:   "1 1/2 1/2\n",
+		pivots: []int{0},
+		null:   []string{"-1/2 1 0", "-1/2 0 1"},
+	},
+	{
+		name:   "zero column",
+		rows:   [][]int64{{0, 1}, {0, 2}},
+		rref:   "0 1\n0 0\n",
+		pivots: []int{1},
+		null:   []string{"1 0"},
+	},
+	{
+		name:    "dependent row mod 5",
+		modulus: 5,
+		rows:    [][]int64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}},
+		rref:    "1 0 1\n0 1 1\n0 0 0\n",
+		pivots:  []int{0, 1},
+		null:    []string{"4 4 1"},
+	},
+	{
+		name:    "fractions mod 7",
+		modulus: 7,
+		rows:    [][]int64{{2, 1, 1}},
+		rref:    "1 4 4\n",
+		pivots:  []int{0},
+		null:    []string{"3 1 0", "3 0 1"},
+	},
+	{
+		name:    "rank drops mod 3",
+		modulus: 3,
+		rows:    [][]int64{{1, 1}, {1, 4}},
+		rref:    "1 1\n0 0\n",
+		pivots:  []int{0},
+		null:    []string{"2 1"},
+	},
+	{
+		name:    "negative entries mod 7",
+		modulus: 7,
+		rows:    [][]int64{{-1, 3}, {2, -6}},
+		rref:    "1 4\n0 0\n",
+		pivots:  []int{0},
+		null:    []string{"3 1"},
+	},
+}
+
+func TestReduction(t *testing.T) {
+	for _, tt := range reductionTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.modulus == 0 {
+				checkReduction(t, NewRatFromInts(tt.rows), tt.rref, tt.pivots, tt.null)
+			} else {
+				checkReduction(t, NewModFromInts(tt.rows, tt.modulus), tt.rref, tt.pivots, tt.null)
+			}
+		})
+	}
+}
+
+func checkReduction[E any](t *testing.T, m *Matrix[E], rref string, pivots []int, null []string) {
+	t.Helper()
+	original := m.String()
+	if got := m.Rank(); got != len(pivots) {
+		t.Errorf("Rank() = %d, want %d", got, len(pivots))
+	}
+	if got := formatVectors(m, m.NullSpace()); !slices.Equal(got, null) {
+		t.Errorf("NullSpace() = %q, want %q", got, null)
+	}
+	if got := m.String(); got != original {
+		t.Errorf("Rank and NullSpace changed the matrix to\n%s", got)
+	}
+
+	if got := m.RREF(); !slices.Equal(got, pivots) {
+		t.Errorf("RREF() pivots = %v, want %v", got, pivots)
+	}
+	if got := m.String(); got != rref {
+		t.Errorf("RREF() =\n%swant\n%s", got, rref)
+	}
+}
+
+// solveTests are systems m·x = b with their solution set, or whether they are
+// inconsistent. Modulus 0 stands for the rationals.
+var solveTests = []struct {
+	name         string
+	modulus      uint64
+	rows         [][]int64
+	b            []int64
+	inconsistent bool
+	particular   string
+	free         []int
+	basis        []string
+}{
+	{
+		name:       "unique",
+		rows:       [][]int64{{2, 1}, {1, 3}},
+		b:          []int64{5, 10},
+		particular: "1 3",
+	},
+	{
+		name:       "unique with fractions",
+		rows:       [][]int64{{1, 1}, {1, 4}},
+		b:          []int64{1, 2},
+		particular: "2/3 1/3",
+	},
+	{
+		name:       "underdetermined",
+		rows:       [][]int64{{1, 2, 3}},
+		b:          []int64{6},
+		particular: "6 0 0",
+		free:       []int{1, 2},
+		basis:      []string{"-2 1 0", "-3 0 1"},
+	},
+	{
+		name:       "redundant equation",
+		rows:       [][]int64{{1, 1}, {2, 2}},
+		b:          []int64{1, 2},
+		particular: "1 0",
+		free:       []int{1},
+		basis:      []string{"-1 1"},
+	},
+	{
+		name:         "inconsistent",
+		rows:         [][]int64{{1, 1}, {2, 2}},
+		b:            []int64{1, 3},
+		inconsistent: true,
+	},
+	{
+		name:       "unique mod 7",
+		modulus:    7,
+		rows:       [][]int64{{2, 1}, {1, 3}},
+		b:          []int64{5, 10},
+		particular: "1 3",
+	},
+	{
+		name:         "inconsistent mod 3",
+		modulus:      3,
+		rows:         [][]int64{{1, 1}, {1, 4}},
+		b:            []int64{1, 2},
+		inconsistent: true,
+	},
+	{
+		name:       "underdetermined mod 5",
+		modulus:    5,
+		rows:       [][]int64{{1, 2, 3}},
+		b:          []int64{6},
+		particular: "1 0 0",
+		free:       []int{1, 2},
+		basis:      []string{"3 1 0", "2 0 1"},
+	},
+}
+
+func TestSolve(t *testing.T) {
+	for _, tt := range solveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.modulus == 0 {
+				m := NewRatFromInts(tt.rows)
+				checkSolve(t, m, m.Vector(tt.b...), tt.inconsistent, tt.particular, tt.free, tt.basis)
+			} else {
+				m := NewModFromInts(tt.rows, tt.modulus)
+				checkSolve(t, m, m.Vector(tt.b...), tt.inconsistent, tt.particular, tt.free, tt.basis)
+			}
+		})
+	}
+}
+
+func checkSolve[E any](t *testing.T, m *Matrix[E], b []E, inconsistent bool, particular string, free []int, basis []string) {
+	t.Helper()
+	s, err := m.Solve(b)
+	if inconsistent {
+		if !errors.Is(err, ErrInconsistent) {
+			t.Fatalf("Solve() error = %v, want %v", err, ErrInconsistent)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if got := formatVector(m, s.Particular); got != particular {
+		t.Errorf("Particular = %q, want %q", got, particular)
+	}
+	if !slices.Equal(s.Free, free) {
+		t.Errorf("Free = %v, want %v", s.Free, free)
+	}
+	if got := formatVectors(m, s.Basis); !slices.Equal(got, basis) {
+		t.Errorf("Basis = %q, want %q", got, basis)
+	}
+	if got, want := s.Unique(), len(free) == 0; got != want {
+		t.Errorf("Unique() = %v, want %v", got, want)
+	}
+
+	// Every point of the solution set solves the system
+	ones := make([]int64, len(s.Free))
+	for i := range ones {
+		ones[i] = int64(i + 1)
+	}
+	x := s.At(m.Vector(ones...))
+	for i := range m.Rows() {
+		sum := m.f.zero()
+		for j := range m.Cols() {
+			sum = m.f.add(sum, m.f.mul(m.At(i, j), x[j]))
+		}
+		if m.f.format(sum) != m.f.format(b[i]) {
+			t.Errorf("row %d of m·x = %s at x = %s, want %s", i, m.f.format(sum), formatVector(m, x), m.f.format(b[i]))
+		}
+	}
+}
+
+func TestSolveWrongLength(t *testing.T) {
+	m := NewRatFromInts([][]int64{{1, 0}, {0, 1}})
+	_, err := m.Solve(m.Vector(1))
+	if err == nil || errors.Is(err, ErrInconsistent) {
+		t.Errorf("Solve() error = %v, want a length mismatch", err)
+	}
+}
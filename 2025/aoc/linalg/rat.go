@@ -0,0 +1,38 @@
+package linalg
+
+import "math/big"
+
+// ScaleToIntegers rewrites a rational solution set with a common denominator, so that
+// denominator·x = particular + t[0]·basis[0] + t[1]·basis[1] + ... holds with integer
+// coefficients. Searching integer solutions can then use machine arithmetic, with x
+// integral exactly when the right-hand side is divisible by the denominator.
+func ScaleToIntegers(s *Solution[*big.Rat]) (denominator *big.Int, particular []*big.Int, basis [][]*big.Int) {
+	denominator = big.NewInt(1)
+	lcm := func(v []*big.Rat) {
+		for _, x := range v {
+			d := x.Denom()
+			g := new(big.Int).GCD(nil, nil, denominator, d)
+			denominator.Mul(denominator, new(big.Int).Quo(d, g))
+		}
+	}
+	lcm(s.Particular)
+	for _, v := range s.Basis {
+		lcm(v)
+	}
+
+	scale := func(v []*big.Rat) []*big.Int {
+		scaled := make([]*big.Int, len(v))
+		for i, x := range v {
+			// x = num/den and den divides the common denominator
+			n := new(big.Int).Quo(denominator, x.Denom())
+			scaled[i] = n.Mul(n, x.Num())
+		}
+		return scaled
+	}
+	particular = scale(s.Particular)
+	basis = make([][]*big.Int, len(s.Basis))
+	for k, v := range s.Basis {
+		basis[k] = scale(v)
+	}
+	return denominator, particular, basis
+}
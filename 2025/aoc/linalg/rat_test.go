@@ -0,0 +1,74 @@
+package linalg
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestScaleToIntegers(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        [][]int64
+		b           []int64
+		denominator string
+		particular  string
+		basis       []string
+	}{
+		{
+			name:        "integral",
+			rows:        [][]int64{{1, 2, 3}},
+			b:           []int64{6},
+			denominator: "1",
+			particular:  "[6 0 0]",
+			basis:       []string{"[-2 1 0]", "[-3 0 1]"},
+		},
+		{
+			name:        "halves",
+			rows:        [][]int64{{2, 1, 1}},
+			b:           []int64{1},
+			denominator: "2",
+			particular:  "[1 0 0]",
+			basis:       []string{"[-1 2 0]", "[-1 0 2]"},
+		},
+		{
+			// The common denominator is the least common multiple, not the product
+			name:        "different denominators",
+			rows:        [][]int64{{2, 0}, {0, 4}},
+			b:           []int64{1, 1},
+			denominator: "4",
+			particular:  "[2 1]",
+		},
+		{
+			name:        "denominator only in the basis",
+			rows:        [][]int64{{3, 1}},
+			b:           []int64{3},
+			denominator: "3",
+			particular:  "[3 0]",
+			basis:       []string{"[-1 3]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRatFromInts(tt.rows)
+			s, err := m.Solve(m.Vector(tt.b...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			denominator, particular, basis := ScaleToIntegers(s)
+			if got := denominator.String(); got != tt.denominator {
+				t.Errorf("denominator = %s, want %s", got, tt.denominator)
+			}
+			if got := fmt.Sprint(particular); got != tt.particular {
+				t.Errorf("particular = %s, want %s", got, tt.particular)
+			}
+			var got []string
+			for _, v := range basis {
+				got = append(got, fmt.Sprint(v))
+			}
+			if !slices.Equal(got, tt.basis) {
+				t.Errorf("basis = %q, want %q", got, tt.basis)
+			}
+		})
+	}
+}
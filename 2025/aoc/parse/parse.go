@@ -0,0 +1,302 @@
+// Package parse reads puzzle inputs into Go values described by struct tags.
+//
+// An input is split into blocks separated by blank lines. The fields of the struct
+// given to File or Reader are its sections, taken from the blocks in order; the
+// aoc tag says how many blocks and lines a section takes:
+//
+//	aoc:"lines"   one block, each line parsed as an element of the slice
+//	aoc:"line"    one block of a single line
+//	aoc:"block"   one block parsed as a block struct, see below
+//	aoc:"blocks"  a slice of block structs taking every block that the sections
+//	              after it do not need; at most one section may be blocks
+//
+// The fields of a block struct take its lines in order: one line each, except for
+// a last field tagged aoc:"rest", which takes the remaining lines. A slice gets
+// one element per line; any other type gets the lines joined with newlines.
+//
+// A line is parsed according to the type it is stored in:
+//
+//   - integers, floats and strings hold the whole text;
+//   - types implementing encoding.TextUnmarshaler parse the text themselves;
+//   - slices split the text by the field's sep tag, or by runs of spaces without one;
+//   - structs split the text among their fields, each field running from its
+//     prefix tag up to its suffix tag, or else up to the next field's prefix, or
+//     else to the end of the text.
+//
+// Any field may give a prefix and a suffix that must surround its text. For example
+// the line "[.##.] (3) (1,3) {3,5,4}" is parsed by
+//
+//	type machine struct {
+//		Lights   lights   `prefix:"[" suffix:"] "` // lights implements TextUnmarshaler
+//		Buttons  []button // elements separated by spaces
+//		Joltages []int    `prefix:" {" suffix:"}" sep:","`
+//	}
+//
+//	type button struct {
+//		Counters []int `prefix:"(" suffix:")" sep:","`
+//	}
+//
+// Errors report the line and column where the input does not match, as an *Error.
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"aoc/input"
+)
+
+// Error is an input that does not match its description.
+type Error struct {
+	Name   string // name of the input, usually a file name, or empty for Line
+	Line   int    // line number, starting at 1
+	Column int    // byte offset in the line, starting at 1
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("column %d: %s", e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.Name, e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// File reads and parses the named input, which may be encrypted, see package input.
+func File[T any](filename string) (T, error) {
+	var result T
+	file, err := input.Open(filename)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+	return Reader[T](file, filename)
+}
+
+// Reader parses a whole input into a struct whose fields are its sections. Errors
+// name the input as name.
+func Reader[T any](r io.Reader, name string) (T, error) {
+	var result T
+	var lines []line
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for number := 1; scanner.Scan(); number++ {
+		lines = append(lines, line{text: strings.TrimSuffix(scanner.Text(), "\r"), number: number})
+	}
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+
+	p := &parser{name: name, lastLine: len(lines)}
+	v := reflect.ValueOf(&result).Elem()
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("parse: %s is not a struct", v.Type()))
+	}
+	return result, p.sections(v, splitBlocks(lines))
+}
+
+// Line parses a single line of text, such as a command argument, into a value.
+func Line[T any](text string) (T, error) {
+	var result T
+	p := &parser{lastLine: 1}
+	err := p.value(reflect.ValueOf(&result).Elem(), text, position{line: 1, column: 1}, "")
+	return result, err
+}
+
+// line is a line of the input with its number.
+type line struct {
+	text   string
+	number int
+}
+
+// splitBlocks groups lines into runs of non-blank lines.
+func splitBlocks(lines []line) [][]line {
+	var blocks [][]line
+	var current []line
+	for _, l := range lines {
+		if strings.TrimSpace(l.text) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, l)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// position is a place in the input, used for errors.
+type position struct {
+	line   int
+	column int
+}
+
+func (p position) advance(n int) position {
+	return position{line: p.line, column: p.column + n}
+}
+
+type parser struct {
+	name     string
+	lastLine int
+}
+
+func (p *parser) errorf(pos position, format string, args ...any) error {
+	return &Error{Name: p.name, Line: pos.line, Column: pos.column, Err: fmt.Errorf(format, args...)}
+}
+
+// endOfInput is the position reported when sections are missing.
+func (p *parser) endOfInput() position {
+	return position{line: p.lastLine + 1, column: 1}
+}
+
+// sections fills the fields of v from the blocks of the input.
+func (p *parser) sections(v reflect.Value, blocks [][]line) error {
+	t := v.Type()
+	fixed, variable := 0, -1
+	for i := range t.NumField() {
+		switch kind := t.Field(i).Tag.Get("aoc"); kind {
+		case "lines", "line", "block":
+			fixed++
+		case "blocks":
+			if variable >= 0 {
+				panic(fmt.Sprintf("parse: %s has more than one blocks section", t))
+			}
+			variable = i
+		default:
+			panic(fmt.Sprintf("parse: section %s.%s has no valid aoc tag: %q", t, t.Field(i).Name, kind))
+		}
+	}
+
+	repeated := len(blocks) - fixed
+	if variable < 0 && repeated > 0 {
+		return p.errorf(position{line: blocks[fixed][0].number, column: 1}, "unexpected section after the last one")
+	}
+
+	next := 0
+	for i := range t.NumField() {
+		field := t.Field(i)
+		kind := field.Tag.Get("aoc")
+		count := 1
+		if kind == "blocks" {
+			count = max(repeated, 0)
+		}
+		if next+count > len(blocks) {
+			return p.errorf(p.endOfInput(), "missing section %s", field.Name)
+		}
+		taken := blocks[next : next+count]
+		next += count
+
+		var err error
+		switch fv := v.Field(i); kind {
+		case "lines":
+			err = p.slice(fv, taken[0], field.Tag)
+		case "line":
+			if len(taken[0]) > 1 {
+				return p.errorf(position{line: taken[0][1].number, column: 1}, "section %s has a single line", field.Name)
+			}
+			err = p.field(fv, taken[0][0].text, position{line: taken[0][0].number, column: 1}, field.Tag)
+		case "block":
+			err = p.block(fv, taken[0])
+		case "blocks":
+			fv.Set(reflect.MakeSlice(fv.Type(), len(taken), len(taken)))
+			for k, block := range taken {
+				if err = p.block(fv.Index(k), block); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// block fills the fields of a block struct from the lines of one block.
+func (p *parser) block(v reflect.Value, lines []line) error {
+	t := v.Type()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("parse: block %s is not a struct", t))
+	}
+
+	next := 0
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if next >= len(lines) {
+			return p.errorf(position{line: lines[len(lines)-1].number + 1, column: 1}, "missing line for %s", field.Name)
+		}
+
+		switch field.Tag.Get("aoc") {
+		case "", "line":
+			if err := p.field(v.Field(i), lines[next].text, position{line: lines[next].number, column: 1}, field.Tag); err != nil {
+				return err
+			}
+			next++
+		case "rest":
+			if i != t.NumField()-1 {
+				panic(fmt.Sprintf("parse: rest field %s.%s is not the last one", t, field.Name))
+			}
+			if err := p.rest(v.Field(i), lines[next:], field.Tag); err != nil {
+				return err
+			}
+			next = len(lines)
+		default:
+			panic(fmt.Sprintf("parse: block field %s.%s has an invalid aoc tag", t, field.Name))
+		}
+	}
+
+	if next < len(lines) {
+		return p.errorf(position{line: lines[next].number, column: 1}, "unexpected line in %s", t.Name())
+	}
+	return nil
+}
+
+// rest fills a field with several lines: one element per line for a slice, or the
+// lines joined by newlines otherwise.
+func (p *parser) rest(v reflect.Value, lines []line, tag reflect.StructTag) error {
+	if v.Kind() == reflect.Slice && !isTextUnmarshaler(v) {
+		return p.slice(v, lines, tag)
+	}
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return p.field(v, strings.Join(texts, "\n"), position{line: lines[0].number, column: 1}, tag)
+}
+
+// slice fills a slice with one element per line.
+func (p *parser) slice(v reflect.Value, lines []line, tag reflect.StructTag) error {
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("parse: lines section of type %s is not a slice", v.Type()))
+	}
+	v.Set(reflect.MakeSlice(v.Type(), len(lines), len(lines)))
+	for i, l := range lines {
+		if err := p.field(v.Index(i), l.text, position{line: l.number, column: 1}, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field parses the text of a field after checking and removing its prefix and suffix.
+func (p *parser) field(v reflect.Value, text string, pos position, tag reflect.StructTag) error {
+	prefix, suffix := tag.Get("prefix"), tag.Get("suffix")
+	if !strings.HasPrefix(text, prefix) {
+		return p.errorf(pos, "expected %q", prefix)
+	}
+	if !strings.HasSuffix(text[len(prefix):], suffix) {
+		return p.errorf(pos.advance(len(text)), "expected %q at the end", suffix)
+	}
+	inner := text[len(prefix) : len(text)-len(suffix)]
+	return p.value(v, inner, pos.advance(len(prefix)), tag.Get("sep"))
+}
@@ -0,0 +1,147 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type lights []bool
+
+func (l *lights) UnmarshalText(text []byte) error {
+	*l = nil
+	for _, c := range text {
+		if c != '.' && c != '#' {
+			return fmt.Errorf("invalid light %q", c)
+		}
+		*l = append(*l, c == '#')
+	}
+	return nil
+}
+
+type button struct {
+	Counters []int `prefix:"(" suffix:")" sep:","`
+}
+
+type machine struct {
+	Lights   lights `prefix:"[" suffix:"] "`
+	Buttons  []button
+	Joltages []int `prefix:" {" suffix:"}" sep:","`
+}
+
+type almanac struct {
+	Seeds  []int     `aoc:"line" prefix:"seeds: "`
+	Ranges []mapping `aoc:"blocks"`
+	Moves  []move    `aoc:"lines"`
+}
+
+type mapping struct {
+	Name string  `suffix:":"`
+	Rows [][]int `aoc:"rest"`
+}
+
+type move struct {
+	Direction string `suffix:" "`
+	Steps     int
+}
+
+// checkError reports whether err is an *Error at the given line and column whose
+// message contains message.
+func checkError(t *testing.T, err error, line, column int, message string) {
+	t.Helper()
+	var parseErr *Error
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v, want a *parse.Error", err)
+	}
+	if parseErr.Line != line || parseErr.Column != column || !strings.Contains(parseErr.Err.Error(), message) {
+		t.Errorf("error at %d:%d %q, want at %d:%d containing %q", parseErr.Line, parseErr.Column, parseErr.Err, line, column, message)
+	}
+}
+
+func TestLine(t *testing.T) {
+	got, err := Line[machine]("[.##.] (3) (1,3) {3,5,4}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := machine{
+		Lights:   lights{false, true, true, false},
+		Buttons:  []button{{[]int{3}}, {[]int{1, 3}}},
+		Joltages: []int{3, 5, 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Line() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLineErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		column  int
+		message string
+	}{
+		{"missing prefix", "(.##.] (3) {1}", 1, `expected "["`},
+		{"text unmarshaler", "[.#x.] (3) {1}", 2, "invalid light 'x'"},
+		{"element of a nested slice", "[.##.] (3) (1,x) {3,5,4}", 15, `invalid integer "x"`},
+		{"missing next prefix", "[.##.] (3)", 11, `expected " {"`},
+		{"missing suffix", "[.##.] (3) {1,2", 16, `expected "}"`},
+		{"trailing text", "[.##.] (3) {1}x", 15, `unexpected "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Line[machine](tt.text)
+			checkError(t, err, 1, tt.column, tt.message)
+			if want := fmt.Sprintf("column %d: ", tt.column); err != nil && !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error = %q, want it to start %q", err, want)
+			}
+		})
+	}
+}
+
+func TestReader(t *testing.T) {
+	const text = "seeds: 1 2 3\n\na:\n1 2\n3 4\n\nb:\n5\n\nL 3\r\nR 4\n"
+	got, err := Reader[almanac](strings.NewReader(text), "input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := almanac{
+		Seeds: []int{1, 2, 3},
+		Ranges: []mapping{
+			{"a", [][]int{{1, 2}, {3, 4}}},
+			{"b", [][]int{{5}}},
+		},
+		Moves: []move{{"L", 3}, {"R", 4}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reader() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		line    int
+		column  int
+		message string
+	}{
+		{"missing prefix", "seed: 1\n\nL 3\n", 1, 1, `expected "seeds: "`},
+		{"invalid element", "seeds: 1 x 3\r\n\r\nL 3\r\n", 1, 10, `invalid integer "x"`},
+		{"second line in a line section", "seeds: 1\nseeds: 2\n\nL 3\n", 2, 1, "section Seeds has a single line"},
+		{"missing suffix in a block", "seeds: 1\n\na\n1 2\n\nL 3\n", 3, 2, `expected ":" at the end`},
+		{"invalid rest line", "seeds: 1\n\na:\n1 2\n3 y\n\nL 3\n", 5, 3, `invalid integer "y"`},
+		{"invalid line in a lines section", "seeds: 1\n\n\n\nL 3\nR four\n", 6, 3, `invalid integer "four"`},
+		{"missing section", "seeds: 1\n", 2, 1, "missing section Moves"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Reader[almanac](strings.NewReader(tt.text), "input.txt")
+			checkError(t, err, tt.line, tt.column, tt.message)
+			if want := fmt.Sprintf("input.txt:%d:%d: ", tt.line, tt.column); err != nil && !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error = %q, want it to start %q", err, want)
+			}
+		})
+	}
+}
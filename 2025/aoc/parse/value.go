@@ -0,0 +1,140 @@
+package parse
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func isTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// value parses text into v. sep separates the elements of a slice.
+func (p *parser) value(v reflect.Value, text string, pos position, sep string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(text)); err != nil {
+			return p.errorf(pos, "%s", err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return p.errorf(pos, "invalid integer %q", text)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return p.errorf(pos, "invalid unsigned integer %q", text)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return p.errorf(pos, "invalid number %q", text)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := split(text, sep)
+		v.Set(reflect.MakeSlice(v.Type(), len(parts), len(parts)))
+		for i, part := range parts {
+			if err := p.value(v.Index(i), part.text, pos.advance(part.offset), ""); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return p.fields(v, text, pos)
+	default:
+		panic(fmt.Sprintf("parse: cannot parse into %s", v.Type()))
+	}
+	return nil
+}
+
+// fields splits the text of a line among the fields of a struct.
+func (p *parser) fields(v reflect.Value, text string, pos position) error {
+	t := v.Type()
+	cursor := 0
+	for i := range t.NumField() {
+		field := t.Field(i)
+		prefix, suffix := field.Tag.Get("prefix"), field.Tag.Get("suffix")
+		if !strings.HasPrefix(text[cursor:], prefix) {
+			return p.errorf(pos.advance(cursor), "expected %q", prefix)
+		}
+		cursor += len(prefix)
+
+		// The field ends at its suffix, or else where the next field starts
+		end, next := len(text), len(text)
+		switch {
+		case suffix != "":
+			k := strings.Index(text[cursor:], suffix)
+			if k < 0 {
+				return p.errorf(pos.advance(len(text)), "expected %q", suffix)
+			}
+			end, next = cursor+k, cursor+k+len(suffix)
+		case i+1 < t.NumField():
+			nextPrefix := t.Field(i + 1).Tag.Get("prefix")
+			if nextPrefix == "" {
+				panic(fmt.Sprintf("parse: field %s.%s needs a suffix, or the next field a prefix", t, field.Name))
+			}
+			k := strings.Index(text[cursor:], nextPrefix)
+			if k < 0 {
+				return p.errorf(pos.advance(len(text)), "expected %q", nextPrefix)
+			}
+			end, next = cursor+k, cursor+k
+		}
+
+		if err := p.value(v.Field(i), text[cursor:end], pos.advance(cursor), field.Tag.Get("sep")); err != nil {
+			return err
+		}
+		cursor = next
+	}
+
+	if cursor < len(text) {
+		return p.errorf(pos.advance(cursor), "unexpected %q", text[cursor:])
+	}
+	return nil
+}
+
+// part is an element of a split text with its offset in the text.
+type part struct {
+	text   string
+	offset int
+}
+
+// split splits text by sep, or by runs of spaces if sep is empty.
+func split(text string, sep string) []part {
+	var parts []part
+	if sep == "" {
+		start := -1
+		for i := 0; i <= len(text); i++ {
+			if i == len(text) || text[i] == ' ' || text[i] == '\t' {
+				if start >= 0 {
+					parts = append(parts, part{text: text[start:i], offset: start})
+					start = -1
+				}
+			} else if start < 0 {
+				start = i
+			}
+		}
+		return parts
+	}
+
+	offset := 0
+	for {
+		k := strings.Index(text[offset:], sep)
+		if k < 0 {
+			return append(parts, part{text: text[offset:], offset: offset})
+		}
+		parts = append(parts, part{text: text[offset : offset+k], offset: offset})
+		offset += k + len(sep)
+	}
+}
@@ -0,0 +1,164 @@
+package polyomino
+
+import (
+	"iter"
+	"math/bits"
+	"strings"
+
+	"aoc/grid"
+)
+
+// Board is a rectangular area on which shapes are placed without overlapping.
+// Each row is a bitset of (width+63)/64 words.
+type Board struct {
+	width  int
+	height int
+	words  int      // words per row
+	cells  []uint64 // row-major, words per row
+	filled int
+}
+
+// NewBoard creates an empty width x height board.
+func NewBoard(width, height int) *Board {
+	words := (width + 63) / 64
+	return &Board{
+		width:  width,
+		height: height,
+		words:  words,
+		cells:  make([]uint64, words*height),
+	}
+}
+
+// Width returns the number of columns.
+func (b *Board) Width() int {
+	return b.width
+}
+
+// Height returns the number of rows.
+func (b *Board) Height() int {
+	return b.height
+}
+
+// Free returns the number of empty cells.
+func (b *Board) Free() int {
+	return b.width*b.height - b.filled
+}
+
+// Clone returns a copy of the board.
+func (b *Board) Clone() *Board {
+	c := *b
+	c.cells = append([]uint64(nil), b.cells...)
+	return &c
+}
+
+// Filled reports whether the cell at p is occupied. Cells outside the board count as occupied.
+func (b *Board) Filled(p grid.Point) bool {
+	if p.X < 0 || p.X >= b.width || p.Y < 0 || p.Y >= b.height {
+		return true
+	}
+	return b.cells[p.Y*b.words+p.X/64]&(1<<(p.X%64)) != 0
+}
+
+// FirstEmpty returns the first empty cell in row-major order.
+// The boolean is false if the board is full.
+func (b *Board) FirstEmpty() (grid.Point, bool) {
+	for y := range b.height {
+		for w := range b.words {
+			word := b.cells[y*b.words+w]
+			if w == b.words-1 && b.width%64 != 0 {
+				word |= ^uint64(0) << (b.width % 64) // cells past the right edge
+			}
+			if word != ^uint64(0) {
+				return grid.Point{X: w*64 + bits.TrailingZeros64(^word), Y: y}, true
+			}
+		}
+	}
+	return grid.Point{}, false
+}
+
+// rowMask returns the bits covered by one shape row placed at column x, split over
+// the word containing x and the next one.
+func rowMask(row uint64, x int) (word int, lo, hi uint64) {
+	shift := x % 64
+	lo = row << shift
+	if shift > 0 {
+		hi = row >> (64 - shift)
+	}
+	return x / 64, lo, hi
+}
+
+// CanPlace reports whether s fits on the board with its top-left corner at p,
+// staying inside the board and covering only empty cells.
+func (b *Board) CanPlace(s Shape, p grid.Point) bool {
+	if p.X < 0 || p.Y < 0 || p.X+s.width > b.width || p.Y+s.height > b.height {
+		return false
+	}
+	for dy, row := range s.rows {
+		base := (p.Y + dy) * b.words
+		word, lo, hi := rowMask(row, p.X)
+		if b.cells[base+word]&lo != 0 {
+			return false
+		}
+		if hi != 0 && b.cells[base+word+1]&hi != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Place fills the cells of s with its top-left corner at p. The placement must be
+// valid according to CanPlace.
+func (b *Board) Place(s Shape, p grid.Point) {
+	b.toggle(s, p)
+	b.filled += s.Area()
+}
+
+// Remove empties the cells of s previously placed at p.
+func (b *Board) Remove(s Shape, p grid.Point) {
+	b.toggle(s, p)
+	b.filled -= s.Area()
+}
+
+func (b *Board) toggle(s Shape, p grid.Point) {
+	for dy, row := range s.rows {
+		base := (p.Y + dy) * b.words
+		word, lo, hi := rowMask(row, p.X)
+		b.cells[base+word] ^= lo
+		if hi != 0 {
+			b.cells[base+word+1] ^= hi
+		}
+	}
+}
+
+// Placements iterates over every position where s can be placed, in row-major order
+// of its top-left corner.
+func (b *Board) Placements(s Shape) iter.Seq[grid.Point] {
+	return func(yield func(grid.Point) bool) {
+		for y := 0; y+s.height <= b.height; y++ {
+			for x := 0; x+s.width <= b.width; x++ {
+				p := grid.Point{X: x, Y: y}
+				if b.CanPlace(s, p) && !yield(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// String renders the board with '#' for filled cells and '.' for empty ones.
+func (b *Board) String() string {
+	var sb strings.Builder
+	for y := range b.height {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		for x := range b.width {
+			if b.Filled(grid.Point{X: x, Y: y}) {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,236 @@
+// Package polyomino provides shapes made of unit squares and boards to place them on.
+//
+// Shapes and boards are stored as bitsets with one word per row, so testing and
+// placing a shape costs one AND or OR per shape row.
+package polyomino
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"aoc/grid"
+)
+
+// MaxWidth is the widest shape that fits in a single row word.
+const MaxWidth = 64
+
+// Shape is a normalized polyomino: its bounding box starts at (0, 0) and touches a
+// filled cell on every side. Bit x of rows[y] is set when cell (x, y) is filled.
+// Shapes are immutable and can be compared with Equal or by Key.
+type Shape struct {
+	width  int
+	height int
+	rows   []uint64
+}
+
+// Parse builds a shape from ASCII art, with '#' for filled cells and '.' for empty ones.
+// Lines may have different lengths; missing cells are empty. The result is normalized.
+func Parse(lines []string) (Shape, error) {
+	var cells []grid.Point
+	for y, line := range lines {
+		for x, c := range line {
+			switch c {
+			case '#':
+				cells = append(cells, grid.Point{X: x, Y: y})
+			case '.':
+			default:
+				return Shape{}, fmt.Errorf("line %d, column %d: unexpected character %q", y+1, x+1, c)
+			}
+		}
+	}
+	if len(cells) == 0 {
+		return Shape{}, fmt.Errorf("shape has no filled cells")
+	}
+	return FromCells(cells)
+}
+
+// MustParse is like Parse but panics on error. It is intended for shapes written in code.
+func MustParse(art string) Shape {
+	s, err := Parse(strings.Split(strings.TrimSpace(art), "\n"))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// UnmarshalText parses ASCII art as Parse does, with lines separated by newlines.
+func (s *Shape) UnmarshalText(text []byte) error {
+	shape, err := Parse(strings.Split(string(text), "\n"))
+	if err != nil {
+		return err
+	}
+	*s = shape
+	return nil
+}
+
+// FromCells builds a normalized shape from the positions of its filled cells.
+func FromCells(cells []grid.Point) (Shape, error) {
+	if len(cells) == 0 {
+		return Shape{}, nil
+	}
+
+	lo, hi := cells[0], cells[0]
+	for _, c := range cells {
+		lo = grid.Point{X: min(lo.X, c.X), Y: min(lo.Y, c.Y)}
+		hi = grid.Point{X: max(hi.X, c.X), Y: max(hi.Y, c.Y)}
+	}
+	if width := hi.X - lo.X + 1; width > MaxWidth {
+		return Shape{}, fmt.Errorf("shape is %d cells wide, at most %d are supported", width, MaxWidth)
+	}
+
+	s := Shape{width: hi.X - lo.X + 1, height: hi.Y - lo.Y + 1}
+	s.rows = make([]uint64, s.height)
+	for _, c := range cells {
+		s.rows[c.Y-lo.Y] |= 1 << (c.X - lo.X)
+	}
+	return s, nil
+}
+
+// Width returns the width of the bounding box.
+func (s Shape) Width() int {
+	return s.width
+}
+
+// Height returns the height of the bounding box.
+func (s Shape) Height() int {
+	return s.height
+}
+
+// Area returns the number of filled cells.
+func (s Shape) Area() int {
+	area := 0
+	for _, row := range s.rows {
+		area += bits.OnesCount64(row)
+	}
+	return area
+}
+
+// Has reports whether the cell at p is filled. Cells outside the bounding box are empty.
+func (s Shape) Has(p grid.Point) bool {
+	if p.X < 0 || p.X >= s.width || p.Y < 0 || p.Y >= s.height {
+		return false
+	}
+	return s.rows[p.Y]&(1<<p.X) != 0
+}
+
+// Cells returns the filled cells in row-major order.
+func (s Shape) Cells() []grid.Point {
+	cells := make([]grid.Point, 0, s.Area())
+	for y, row := range s.rows {
+		for row != 0 {
+			x := bits.TrailingZeros64(row)
+			cells = append(cells, grid.Point{X: x, Y: y})
+			row &= row - 1
+		}
+	}
+	return cells
+}
+
+// transform maps every cell through f and normalizes the result.
+func (s Shape) transform(f func(p grid.Point) grid.Point) Shape {
+	cells := s.Cells()
+	for i, c := range cells {
+		cells[i] = f(c)
+	}
+	// Rotating swaps width and height, so only shapes at most MaxWidth tall can be rotated
+	t, err := FromCells(cells)
+	if err != nil {
+		panic("polyomino: " + err.Error())
+	}
+	return t
+}
+
+// RotateClockwise returns the shape turned a quarter turn clockwise.
+func (s Shape) RotateClockwise() Shape {
+	return s.transform(func(p grid.Point) grid.Point { return grid.Point{X: -p.Y, Y: p.X} })
+}
+
+// FlipHorizontal returns the shape mirrored left to right.
+func (s Shape) FlipHorizontal() Shape {
+	return s.transform(func(p grid.Point) grid.Point { return grid.Point{X: -p.X, Y: p.Y} })
+}
+
+// Rotations returns the distinct rotations of the shape, starting with the shape itself.
+func (s Shape) Rotations() []Shape {
+	var result []Shape
+	current := s
+	for range 4 {
+		result = appendDistinct(result, current)
+		current = current.RotateClockwise()
+	}
+	return result
+}
+
+// Symmetries returns the distinct orientations of the shape under rotation and
+// reflection, at most eight. The rotations of the shape itself come first.
+func (s Shape) Symmetries() []Shape {
+	result := s.Rotations()
+	for _, r := range s.FlipHorizontal().Rotations() {
+		result = appendDistinct(result, r)
+	}
+	return result
+}
+
+func appendDistinct(shapes []Shape, s Shape) []Shape {
+	for _, t := range shapes {
+		if t.Equal(s) {
+			return shapes
+		}
+	}
+	return append(shapes, s)
+}
+
+// Equal reports whether s and t have the same cells.
+func (s Shape) Equal(t Shape) bool {
+	if s.width != t.width || s.height != t.height {
+		return false
+	}
+	for y := range s.rows {
+		if s.rows[y] != t.rows[y] {
+			return false
+		}
+	}
+	return true
+}
+
+// Key returns a string that is equal for two shapes exactly when they are Equal,
+// for use as a map key.
+func (s Shape) Key() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%dx%d", s.width, s.height)
+	for _, row := range s.rows {
+		fmt.Fprintf(&sb, ":%x", row)
+	}
+	return sb.String()
+}
+
+// Canonical returns the same shape for all orientations of a polyomino, so free
+// polyominoes can be compared with Equal or Key.
+func (s Shape) Canonical() Shape {
+	best := s
+	for _, t := range s.Symmetries() {
+		if t.Key() < best.Key() {
+			best = t
+		}
+	}
+	return best
+}
+
+// String renders the shape as ASCII art accepted by Parse, one row per line.
+func (s Shape) String() string {
+	var sb strings.Builder
+	for y := range s.height {
+		if y > 0 {
+			sb.WriteByte('\n')
+		}
+		for x := range s.width {
+			if s.rows[y]&(1<<x) != 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,73 @@
+package polyomino
+
+import "testing"
+
+func TestSymmetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		art        string
+		rotations  int
+		symmetries int
+	}{
+		{"monomino", "#", 1, 1},
+		{"domino", "##", 2, 2},
+		{"square", "##\n##", 1, 1},
+		{"line", "####", 2, 2},
+		{"T", "###\n.#.", 4, 4},
+		{"S", ".##\n##.", 2, 4},
+		{"L", "#.\n#.\n##", 4, 8},
+		{"X", ".#.\n###\n.#.", 1, 1},
+		{"F", ".##\n##.\n.#.", 4, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := MustParse(tt.art)
+			if got := len(s.Rotations()); got != tt.rotations {
+				t.Errorf("Rotations() has %d shapes, want %d", got, tt.rotations)
+			}
+			symmetries := s.Symmetries()
+			if got := len(symmetries); got != tt.symmetries {
+				t.Errorf("Symmetries() has %d shapes, want %d", got, tt.symmetries)
+			}
+			if !symmetries[0].Equal(s) {
+				t.Errorf("Symmetries() starts with\n%s\nwant the shape itself", symmetries[0])
+			}
+
+			seen := map[string]bool{}
+			for _, o := range symmetries {
+				if seen[o.Key()] {
+					t.Errorf("Symmetries() has\n%s\ntwice", o)
+				}
+				seen[o.Key()] = true
+				if o.Area() != s.Area() {
+					t.Errorf("orientation\n%s\nhas area %d, want %d", o, o.Area(), s.Area())
+				}
+				if !o.Canonical().Equal(s.Canonical()) {
+					t.Errorf("Canonical() of\n%s\nis\n%s\nwant\n%s", o, o.Canonical(), s.Canonical())
+				}
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"rotated", "###\n.#.", "#.\n##\n#.", true},
+		{"mirrored", ".##\n##.", "##.\n.##", true},
+		{"rotated and mirrored", "#.\n#.\n##", "###\n#..", true},
+		{"same area", "#.\n#.\n##", "###\n.#.", false},
+		{"line and square", "####", "##\n##", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := MustParse(tt.a).Canonical(), MustParse(tt.b).Canonical()
+			if got := a.Key() == b.Key(); got != tt.same {
+				t.Errorf("Canonical() gives\n%s\nand\n%s\nsame = %v, want %v", a, b, got, tt.same)
+			}
+		})
+	}
+}
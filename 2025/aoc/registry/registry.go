@@ -0,0 +1,277 @@
+// Package registry describes the solutions of every year, whatever language they are
+// written in, from a metadata file kept in each day's directory.
+//
+// The repository holds a directory per year, and in it a directory per day:
+//
+//	2021/01/main.py
+//	2025/08/main.go
+//
+// Each day describes its solution in a day.toml:
+//
+//	language = "go"
+//	parts = 2
+//
+//	[input.input1]
+//	file = "input1.txt"
+//	part1 = "20"
+//	part2 = "25272"
+//
+//	[input.input1.options]
+//	connections = 10
+//
+// parts is how many parts are solved, counting from part 1. Each input table names an
+// input of the day and the answers expected on it, for the parts it is solved for. An
+// input's options table overrides the day's options in aoc.toml when solving it, for
+// examples whose puzzle parameters differ from the real input's.
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aoc/config"
+	"aoc/input"
+)
+
+// MetaFile is the name of the metadata file in each day's directory.
+const MetaFile = "day.toml"
+
+// Parts is the number of parts of a puzzle.
+const Parts = 2
+
+// Days is the number of days of an event.
+const Days = 25
+
+// Languages maps the languages solutions are written in to their entry point.
+var Languages = map[string]string{
+	"go":     "main.go",
+	"python": "main.py",
+}
+
+// ErrNoMetadata is returned by Load for a day without a MetaFile.
+var ErrNoMetadata = errors.New("no " + MetaFile)
+
+// Year is an event and the days solved for it.
+type Year struct {
+	Number int
+	Dir    string
+	Days   []*Day
+}
+
+// Day is the solution of one puzzle.
+type Day struct {
+	Year     int
+	Number   int
+	Dir      string
+	Language string
+	Parts    int
+	Inputs   []Input
+}
+
+// Input is an input of a day with the answers expected on it.
+type Input struct {
+	Name    string
+	File    string
+	Answers map[int]string // by part
+	Options config.Table   // overriding the day's, nil if none
+}
+
+// Scan finds the years in root and loads the metadata of their days. Directories
+// named like a year, 2015 and later, hold days in directories named 01 to 25.
+// Days without metadata are returned with an empty Language.
+func Scan(root string) ([]*Year, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var years []*Year
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if !entry.IsDir() || err != nil || len(entry.Name()) != 4 || number < 2015 {
+			continue
+		}
+		year := &Year{Number: number, Dir: filepath.Join(root, entry.Name())}
+		if year.Days, err = scanYear(year); err != nil {
+			return nil, err
+		}
+		years = append(years, year)
+	}
+	return years, nil
+}
+
+func scanYear(year *Year) ([]*Day, error) {
+	entries, err := os.ReadDir(year.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []*Day
+	for _, entry := range entries {
+		number, err := strconv.Atoi(entry.Name())
+		if !entry.IsDir() || err != nil || len(entry.Name()) != 2 || number < 1 || number > Days {
+			continue
+		}
+		dir := filepath.Join(year.Dir, entry.Name())
+		day, err := Load(dir)
+		if errors.Is(err, ErrNoMetadata) {
+			day = &Day{Dir: dir}
+		} else if err != nil {
+			return nil, err
+		}
+		day.Year, day.Number = year.Number, number
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Load reads the metadata of the day in dir.
+func Load(dir string) (*Day, error) {
+	path := filepath.Join(dir, MetaFile)
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNoMetadata)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tables, err := config.Parse(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	day := &Day{Dir: dir}
+	for _, key := range slices.Sorted(maps.Keys(tables[""])) {
+		value := tables[""][key]
+		var ok bool
+		switch key {
+		case "language":
+			day.Language, ok = value.(string)
+			if _, known := Languages[day.Language]; ok && !known {
+				return nil, fmt.Errorf("%s: unknown language %s", path, day.Language)
+			}
+		case "parts":
+			var parts int64
+			parts, ok = value.(int64)
+			day.Parts = int(parts)
+			if ok && (parts < 0 || parts > Parts) {
+				return nil, fmt.Errorf("%s: parts must be from 0 to %d", path, Parts)
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown setting %s", path, key)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: %s has the wrong type", path, key)
+		}
+	}
+	if day.Language == "" {
+		return nil, fmt.Errorf("%s: language is not set", path)
+	}
+
+	options := map[string]config.Table{}
+	for _, name := range slices.Sorted(maps.Keys(tables)) {
+		if name == "" {
+			continue
+		}
+		inputName, ok := strings.CutPrefix(name, "input.")
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown table %s; inputs are named like [input.input1]", path, name)
+		}
+		if inputName, ok := strings.CutSuffix(inputName, ".options"); ok {
+			options[inputName] = tables[name]
+			continue
+		}
+		in, err := loadInput(inputName, tables[name], day.Parts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: input %s: %w", path, inputName, err)
+		}
+		day.Inputs = append(day.Inputs, in)
+	}
+	for i, in := range day.Inputs {
+		day.Inputs[i].Options = options[in.Name]
+		delete(options, in.Name)
+	}
+	if len(options) > 0 {
+		name := slices.Sorted(maps.Keys(options))[0]
+		return nil, fmt.Errorf("%s: options for input %s, which is not listed", path, name)
+	}
+	return day, nil
+}
+
+// Input returns the input of the day read from the file name, or false if the day does
+// not list it. Only the base name of the file is compared, since inputs may be kept in
+// another directory.
+func (d *Day) Input(name string) (Input, bool) {
+	for _, in := range d.Inputs {
+		if filepath.Base(in.File) == filepath.Base(name) {
+			return in, true
+		}
+	}
+	return Input{}, false
+}
+
+func loadInput(name string, table config.Table, parts int) (Input, error) {
+	in := Input{Name: name, Answers: map[int]string{}}
+	for _, key := range slices.Sorted(maps.Keys(table)) {
+		value := table[key]
+		if key == "file" {
+			file, ok := value.(string)
+			if !ok {
+				return in, fmt.Errorf("file has the wrong type")
+			}
+			in.File = file
+			continue
+		}
+
+		part, err := strconv.Atoi(strings.TrimPrefix(key, "part"))
+		if !strings.HasPrefix(key, "part") || err != nil || part < 1 || part > Parts {
+			return in, fmt.Errorf("unknown setting %s", key)
+		}
+		if part > parts {
+			return in, fmt.Errorf("has an answer for part %d, which is not solved", part)
+		}
+		// Answers may be written as numbers, but are compared as text
+		in.Answers[part] = fmt.Sprint(value)
+	}
+	if in.File == "" {
+		return in, fmt.Errorf("file is not set")
+	}
+	return in, nil
+}
+
+// Problems lists what is missing from the day: its metadata, its entry point, parts
+// not solved yet, or inputs not found in the clear or encrypted.
+func (d *Day) Problems() []string {
+	if d.Language == "" {
+		return []string{"no " + MetaFile}
+	}
+
+	var problems []string
+	entry := Languages[d.Language]
+	if _, err := os.Stat(filepath.Join(d.Dir, entry)); err != nil {
+		problems = append(problems, fmt.Sprintf("%s solution without %s", d.Language, entry))
+	}
+	for part := d.Parts + 1; part <= Parts; part++ {
+		problems = append(problems, fmt.Sprintf("part %d is not solved", part))
+	}
+	for _, in := range d.Inputs {
+		if !exists(filepath.Join(d.Dir, in.File)) && !exists(filepath.Join(d.Dir, in.File+input.Ext)) {
+			problems = append(problems, fmt.Sprintf("input %s is missing", in.File))
+		}
+	}
+	return problems
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
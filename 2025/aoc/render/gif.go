@@ -0,0 +1,129 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"aoc/anim"
+	"aoc/grid"
+)
+
+// GIFOptions controls how frames are turned into an animated GIF.
+type GIFOptions struct {
+	// Scale is the size of a cell in pixels. If 0, cells are sized so the image is
+	// about 600 pixels across.
+	Scale int
+	// Delay is the time each frame is shown. If 0, frames are shown for 100ms.
+	Delay time.Duration
+	// Colours maps cell characters to colours. Characters not listed use the defaults:
+	// '.' and ' ' are the background, '#' and '@' are dark and anything else is grey.
+	Colours map[rune]color.Color
+}
+
+var (
+	background = color.RGBA{0xf8, 0xf9, 0xfa, 0xff}
+	foreground = color.RGBA{0x34, 0x3a, 0x40, 0xff}
+	other      = color.RGBA{0xad, 0xb5, 0xbd, 0xff}
+)
+
+// highlightColours are the GIF equivalents of the terminal colours of anim.
+var highlightColours = map[anim.Colour]color.Color{
+	anim.Red:     color.RGBA{0xe6, 0x39, 0x46, 0xff},
+	anim.Green:   color.RGBA{0x2a, 0x9d, 0x8f, 0xff},
+	anim.Yellow:  color.RGBA{0xe9, 0xc4, 0x6a, 0xff},
+	anim.Blue:    color.RGBA{0x19, 0x82, 0xc4, 0xff},
+	anim.Magenta: color.RGBA{0x6a, 0x4c, 0x93, 0xff},
+	anim.Cyan:    color.RGBA{0x4c, 0xc9, 0xf0, 0xff},
+}
+
+func (o GIFOptions) cellColour(c rune) color.Color {
+	if colour, ok := o.Colours[c]; ok {
+		return colour
+	}
+	switch c {
+	case '.', ' ':
+		return background
+	case '#', '@':
+		return foreground
+	}
+	return other
+}
+
+// WriteGIF encodes the frames as an animated GIF that loops forever, drawing each
+// cell as a square and highlighted cells in their highlight colour. Captions are
+// not drawn.
+func WriteGIF(w io.Writer, frames []anim.Frame, opts GIFOptions) error {
+	width, height := 1, 1
+	for _, f := range frames {
+		height = max(height, len(f.Rows))
+		for _, row := range f.Rows {
+			width = max(width, len([]rune(row)))
+		}
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = max(1, 600/max(width, height))
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+
+	// Build one palette shared by every frame, in a stable order
+	colours := color.Palette{background, foreground, other}
+	index := map[color.Color]uint8{}
+	for i, c := range colours {
+		index[c] = uint8(i)
+	}
+	lookup := func(c color.Color) uint8 {
+		if i, ok := index[c]; ok {
+			return i
+		}
+		// GIF palettes hold 256 colours; extra custom colours share the last slot
+		if len(colours) == 256 {
+			return 255
+		}
+		index[c] = uint8(len(colours))
+		colours = append(colours, c)
+		return index[c]
+	}
+	for _, colour := range []anim.Colour{anim.Red, anim.Green, anim.Yellow, anim.Blue, anim.Magenta, anim.Cyan} {
+		lookup(highlightColours[colour])
+	}
+
+	animation := &gif.GIF{}
+	for _, f := range frames {
+		img := image.NewPaletted(image.Rect(0, 0, width*scale, height*scale), nil)
+		for y, row := range f.Rows {
+			x := 0
+			for _, c := range row {
+				colour := opts.cellColour(c)
+				if highlight, ok := f.Highlights[grid.Point{X: x, Y: y}]; ok {
+					colour = highlightColours[highlight]
+				}
+				fillCell(img, x, y, scale, lookup(colour))
+				x++
+			}
+		}
+		animation.Image = append(animation.Image, img)
+		animation.Delay = append(animation.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	// Every frame shares the final palette
+	for _, img := range animation.Image {
+		img.Palette = colours
+	}
+	return gif.EncodeAll(w, animation)
+}
+
+func fillCell(img *image.Paletted, x, y, scale int, colour uint8) {
+	for py := y * scale; py < (y+1)*scale; py++ {
+		row := img.Pix[py*img.Stride:]
+		for px := x * scale; px < (x+1)*scale; px++ {
+			row[px] = colour
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package render exports puzzle states as images using only the standard library:
+// hand-written SVG drawings and animated GIFs built from anim frames.
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"aoc/geom"
+)
+
+// Style describes how a shape is painted. Empty colours are not painted.
+// Sizes are in output pixels, independent of the puzzle's coordinates.
+type Style struct {
+	Fill        string  // fill colour, e.g. "#2a9d8f" or "none"
+	Stroke      string  // outline colour
+	StrokeWidth float64 // outline width in pixels
+	Opacity     float64 // 0 means fully opaque
+}
+
+func (st Style) attributes() string {
+	var sb strings.Builder
+	fill := st.Fill
+	if fill == "" {
+		fill = "none"
+	}
+	fmt.Fprintf(&sb, ` fill="%s"`, html.EscapeString(fill))
+	if st.Stroke != "" {
+		fmt.Fprintf(&sb, ` stroke="%s" stroke-width="%g" vector-effect="non-scaling-stroke"`,
+			html.EscapeString(st.Stroke), max(st.StrokeWidth, 1))
+	}
+	if st.Opacity > 0 && st.Opacity < 1 {
+		fmt.Fprintf(&sb, ` opacity="%g"`, st.Opacity)
+	}
+	return sb.String()
+}
+
+// element is a shape waiting to be written. Markers and text are sized in pixels,
+// which depends on the final scale, so elements are rendered only when writing.
+type element func(scale float64) string
+
+// SVG is a drawing in puzzle coordinates. Its view box grows to fit everything drawn,
+// and it is scaled to a fixed output width when written. Y grows downwards, as on
+// puzzle grids.
+type SVG struct {
+	width    int
+	elements []element
+	bounds   geom.Rect
+	empty    bool
+}
+
+// NewSVG creates an empty drawing that is written width pixels wide.
+func NewSVG(width int) *SVG {
+	return &SVG{width: width, empty: true}
+}
+
+// Empty reports whether nothing has been drawn.
+func (s *SVG) Empty() bool {
+	return s.empty
+}
+
+func (s *SVG) include(points ...geom.Point2) {
+	for _, p := range points {
+		if s.empty {
+			s.bounds = geom.Rect{Min: p, Max: p}
+			s.empty = false
+			continue
+		}
+		s.bounds = geom.RectFromCorners(
+			geom.Point2{X: min(s.bounds.Min.X, p.X), Y: min(s.bounds.Min.Y, p.Y)},
+			geom.Point2{X: max(s.bounds.Max.X, p.X), Y: max(s.bounds.Max.Y, p.Y)},
+		)
+	}
+}
+
+func formatPoints(points []geom.Point2) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%d,%d", p.X, p.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Polygon draws a closed polygon.
+func (s *SVG) Polygon(points []geom.Point2, style Style) {
+	s.include(points...)
+	s.elements = append(s.elements, func(float64) string {
+		return fmt.Sprintf(`<polygon points="%s"%s/>`, formatPoints(points), style.attributes())
+	})
+}
+
+// Polyline draws connected line segments.
+func (s *SVG) Polyline(points []geom.Point2, style Style) {
+	s.include(points...)
+	s.elements = append(s.elements, func(float64) string {
+		return fmt.Sprintf(`<polyline points="%s"%s/>`, formatPoints(points), style.attributes())
+	})
+}
+
+// Line draws the segment from a to b.
+func (s *SVG) Line(a, b geom.Point2, style Style) {
+	s.Polyline([]geom.Point2{a, b}, style)
+}
+
+// Rect draws the rectangle with opposite corners a and b.
+func (s *SVG) Rect(a, b geom.Point2, style Style) {
+	r := geom.RectFromCorners(a, b)
+	s.include(r.Min, r.Max)
+	s.elements = append(s.elements, func(float64) string {
+		return fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d"%s/>`,
+			r.Min.X, r.Min.Y, r.Max.X-r.Min.X, r.Max.Y-r.Min.Y, style.attributes())
+	})
+}
+
+// Cell draws the unit square whose top-left corner is p, as a grid cell.
+func (s *SVG) Cell(p geom.Point2, style Style) {
+	s.Rect(p, p.Add(geom.Point2{X: 1, Y: 1}), style)
+}
+
+// Marker draws a dot at p with a radius in pixels.
+func (s *SVG) Marker(p geom.Point2, radius float64, style Style) {
+	s.include(p)
+	s.elements = append(s.elements, func(scale float64) string {
+		return fmt.Sprintf(`<circle cx="%d" cy="%d" r="%g"%s/>`, p.X, p.Y, radius/scale, style.attributes())
+	})
+}
+
+// Text writes a label whose baseline starts at p, with a font size in pixels.
+func (s *SVG) Text(p geom.Point2, text string, size float64, style Style) {
+	s.include(p)
+	s.elements = append(s.elements, func(scale float64) string {
+		return fmt.Sprintf(`<text x="%d" y="%d" font-family="monospace" font-size="%g"%s>%s</text>`,
+			p.X, p.Y, size/scale, style.attributes(), html.EscapeString(text))
+	})
+}
+
+// WriteTo writes the drawing as an SVG document, with a small margin around it.
+func (s *SVG) WriteTo(w io.Writer) (int64, error) {
+	bounds := s.bounds
+	spanX := max(bounds.Max.X-bounds.Min.X, 1)
+	spanY := max(bounds.Max.Y-bounds.Min.Y, 1)
+	margin := max(spanX, spanY) / 50
+	if margin == 0 {
+		margin = 1
+	}
+	viewX, viewY := bounds.Min.X-margin, bounds.Min.Y-margin
+	viewW, viewH := spanX+2*margin, spanY+2*margin
+
+	scale := float64(s.width) / float64(viewW)
+	height := int(float64(viewH) * scale)
+
+	counter := &countingWriter{w: w}
+	bw := bufio.NewWriter(counter)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%d %d %d %d">`+"\n",
+		s.width, height, viewX, viewY, viewW, viewH)
+	fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="white"/>`+"\n", viewX, viewY, viewW, viewH)
+	for _, e := range s.elements {
+		bw.WriteString(e(scale))
+		bw.WriteByte('\n')
+	}
+	bw.WriteString("</svg>\n")
+	err := bw.Flush()
+	return counter.n, err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// palette holds distinct colours for categories such as circuits or pieces.
+var palette = []string{
+	"#e63946", "#2a9d8f", "#e9c46a", "#264653", "#f4a261",
+	"#6a4c93", "#1982c4", "#8ac926", "#ff595e", "#6d597a",
+}
+
+// PaletteColour returns a colour for category i, cycling through a fixed palette.
+func PaletteColour(i int) string {
+	return palette[i%len(palette)]
+}
@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// allocations counts what has been allocated on the heap.
+type allocations struct {
+	bytes   uint64
+	objects uint64
+}
+
+// readAllocations returns what the program has allocated so far. The counts only
+// grow, so the difference between two readings is what was allocated in between,
+// even if the garbage collector freed some of it.
+func readAllocations() allocations {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return allocations{bytes: stats.TotalAlloc, objects: stats.Mallocs}
+}
+
+func (a allocations) since(before allocations) allocations {
+	return allocations{bytes: a.bytes - before.bytes, objects: a.objects - before.objects}
+}
+
+func (a allocations) String() string {
+	return fmt.Sprintf("%s in %d objects", formatBytes(a.bytes), a.objects)
+}
+
+// formatBytes formats a number of bytes in the largest unit that keeps it at least 1,
+// like 1.5 MiB.
+func formatBytes(n uint64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, unit := float64(n)/1024, 0
+	for value >= 1024 && unit < 3 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, []string{"KiB", "MiB", "GiB", "TiB"}[unit])
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aoc/input"
+)
+
+// anonymize rewrites an input with the day's Anonymize hook, solves the requested parts on
+// both versions and writes the new answers next to the new input, so that the pair can
+// be committed as a test fixture instead of the real puzzle input. If an answer comes
+// out unchanged the new input is removed and nothing is written, since the fixture
+// would give the real answer away.
+func anonymize(day Day, options *Options, part int, source, target string, seed uint64) {
+	if day.Anonymize == nil {
+		log.Fatalf("this day has no input anonymizer")
+	}
+	if source == "" {
+		log.Fatalf("-anonymize needs the input to rewrite, given with -input")
+	}
+
+	lines := readLines(source)
+	rng := rand.New(rand.NewPCG(seed, seed))
+	anonymized := day.Anonymize(lines, rng, options)
+
+	if err := os.WriteFile(target, []byte(strings.Join(anonymized, "\n")+"\n"), 0o644); err != nil {
+		log.Fatalf("failed to write anonymized input: %s", err)
+	}
+
+	var answers strings.Builder
+	for i, p := range day.Parts {
+		if part != 0 && part != i+1 {
+			continue
+		}
+
+		original := p.solve(&Run{Input: source, Out: io.Discard, Options: options})
+		rewritten := p.solve(&Run{Input: target, Out: io.Discard, Options: options})
+
+		fmt.Fprintf(&answers, "Part %d: %v\n", i+1, rewritten)
+		fmt.Printf("Part %d: %v on %s, %v on %s\n", i+1, original, source, rewritten, target)
+		if fmt.Sprint(original) == fmt.Sprint(rewritten) {
+			os.Remove(target)
+			log.Fatalf("part %d: the answer is unchanged, so the fixture would give the real one away; try another -seed or -part", i+1)
+		}
+	}
+
+	answersFile := strings.TrimSuffix(target, filepath.Ext(target)) + ".answers.txt"
+	if err := os.WriteFile(answersFile, []byte(answers.String()), 0o644); err != nil {
+		log.Fatalf("failed to write answers: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s and %s\n", target, answersFile)
+}
+
+// readLines returns the lines of a file without their line endings.
+func readLines(filename string) []string {
+	file, err := input.Open(filename)
+	if err != nil {
+		log.Fatalf("failed to open file: %s", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("error reading file: %s", err)
+	}
+	return lines
+}
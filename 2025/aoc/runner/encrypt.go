@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+
+	"aoc/input"
+)
+
+// encrypt writes an encrypted copy of an input next to it, using the passphrase in the
+// environment, and checks that the copy decrypts back to the original.
+func encrypt(source string) {
+	if source == "" {
+		log.Fatalf("-encrypt needs the input to encrypt, given with -input")
+	}
+	passphrase := os.Getenv(input.KeyEnv)
+	if passphrase == "" {
+		log.Fatalf("-encrypt needs the passphrase in %s", input.KeyEnv)
+	}
+
+	plain, err := os.ReadFile(source)
+	if err != nil {
+		log.Fatalf("failed to read input: %s", err)
+	}
+	encrypted, err := input.Encrypt(plain, passphrase)
+	if err != nil {
+		log.Fatalf("failed to encrypt input: %s", err)
+	}
+	if decrypted, err := input.Decrypt(encrypted, passphrase); err != nil || !bytes.Equal(decrypted, plain) {
+		log.Fatalf("encrypted input does not decrypt back to the original")
+	}
+
+	target := source + input.Ext
+	if err := os.WriteFile(target, encrypted, 0o644); err != nil {
+		log.Fatalf("failed to write encrypted input: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s; commit it instead of %s\n", target, source)
+}
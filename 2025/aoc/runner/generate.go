@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// generate writes a random input of about the given size with the day's Generate hook.
+func generate(day Day, target, size string, seed uint64) {
+	if day.Generate == nil {
+		log.Fatalf("this day has no input generator")
+	}
+	bytes, err := parseSize(size)
+	if err != nil {
+		log.Fatalf("invalid -size: %s", err)
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		log.Fatalf("failed to create generated input: %s", err)
+	}
+	w := bufio.NewWriterSize(file, 1<<20)
+	rng := rand.New(rand.NewPCG(seed, seed))
+	if err := day.Generate(w, bytes, rng); err != nil {
+		log.Fatalf("failed to generate input: %s", err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("failed to write generated input: %s", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		log.Fatalf("failed to write generated input: %s", err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatalf("failed to write generated input: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s, %d bytes\n", target, info.Size())
+}
+
+// parseSize parses a number of bytes with an optional K, M or G suffix for powers of 1024.
+func parseSize(s string) (int64, error) {
+	digits, multiplier := strings.ToUpper(s), int64(1)
+	for i, suffix := range []string{"K", "M", "G"} {
+		if trimmed, ok := strings.CutSuffix(digits, suffix); ok {
+			digits, multiplier = trimmed, 1<<(10*(i+1))
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a positive size", s)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+)
+
+// Issue is a problem found in an input by a day's Lint hook.
+type Issue struct {
+	Line    int // line number counted from 1, or 0 for the input as a whole
+	Message string
+}
+
+// Issuef returns an issue on a line with a formatted message.
+func Issuef(line int, format string, args ...any) Issue {
+	return Issue{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// lint checks an input with the day's Lint hook, printing every issue as file:line:
+// message, and exits with an error if there is any.
+func lint(day Day, options *Options, filename string) {
+	if day.Lint == nil {
+		log.Fatalf("this day has no input linter")
+	}
+	if filename == "" {
+		log.Fatalf("-lint needs the input to check, given with -input")
+	}
+
+	// Issues with the whole input come after the ones on a line
+	issues := day.Lint(readLines(filename), options)
+	order := func(issue Issue) int {
+		if issue.Line == 0 {
+			return math.MaxInt
+		}
+		return issue.Line
+	}
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		return cmp.Compare(order(a), order(b))
+	})
+	for _, issue := range issues {
+		if issue.Line == 0 {
+			fmt.Printf("%s: %s\n", filename, issue.Message)
+		} else {
+			fmt.Printf("%s:%d: %s\n", filename, issue.Line, issue.Message)
+		}
+	}
+	if len(issues) > 0 {
+		if len(issues) == 1 {
+			fmt.Fprintln(os.Stderr, "1 problem found")
+		} else {
+			fmt.Fprintf(os.Stderr, "%d problems found\n", len(issues))
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("%s: ok\n", filename)
+}
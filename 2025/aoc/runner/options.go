@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"aoc/config"
+)
+
+// Options are a day's named parameters: its table in aoc.toml, overridden for an input
+// by the input's options in day.toml, and overridden by -set flags. Solvers read them
+// with a default for when they are not set:
+//
+//	cutoff := run.Options.Int("connections", 1000)
+//
+// A nil Options has no parameters, so every read returns its default. Options may be
+// read by solvers running at the same time.
+type Options struct {
+	day       int
+	values    config.Table
+	overrides config.Table // from -set, which win over an input's options
+
+	// Shared with the options of each input, so that warnUnused sees their reads
+	mu   *sync.Mutex
+	used map[string]bool
+}
+
+func newOptions(day int, values, overrides config.Table) *Options {
+	merged := maps.Clone(values)
+	if merged == nil {
+		merged = config.Table{}
+	}
+	maps.Copy(merged, overrides)
+	return &Options{day: day, values: merged, overrides: overrides, mu: new(sync.Mutex), used: map[string]bool{}}
+}
+
+// ForInput returns the options for solving an input that sets some of its own, as
+// listed in day.toml. They override the day's, and -set flags override both. Reading
+// an option through them counts as reading it through o.
+func (o *Options) ForInput(values config.Table) *Options {
+	if len(values) == 0 {
+		return o
+	}
+	if o == nil {
+		return newOptions(0, values, nil)
+	}
+	merged := maps.Clone(o.values)
+	maps.Copy(merged, values)
+	maps.Copy(merged, o.overrides)
+	return &Options{day: o.day, values: merged, overrides: o.overrides, mu: o.mu, used: o.used}
+}
+
+// lookup returns the value of an option and records that it was read.
+func (o *Options) lookup(name string) (any, bool) {
+	if o == nil {
+		return nil, false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.used[name] = true
+	value, ok := o.values[name]
+	return value, ok
+}
+
+// Int returns the integer option name, or def if it is not set.
+func (o *Options) Int(name string, def int) int {
+	value, ok := o.lookup(name)
+	if !ok {
+		return def
+	}
+	n, ok := value.(int64)
+	if !ok {
+		log.Fatalf("option %s of day %d must be an integer, not %v", name, o.day, value)
+	}
+	return int(n)
+}
+
+// String returns the string option name, or def if it is not set.
+func (o *Options) String(name string, def string) string {
+	value, ok := o.lookup(name)
+	if !ok {
+		return def
+	}
+	s, ok := value.(string)
+	if !ok {
+		log.Fatalf("option %s of day %d must be a string, not %v", name, o.day, value)
+	}
+	return s
+}
+
+// Bool returns the boolean option name, or def if it is not set.
+func (o *Options) Bool(name string, def bool) bool {
+	value, ok := o.lookup(name)
+	if !ok {
+		return def
+	}
+	b, ok := value.(bool)
+	if !ok {
+		log.Fatalf("option %s of day %d must be true or false, not %v", name, o.day, value)
+	}
+	return b
+}
+
+// warnUnused reports options that no solver read, which are probably misspelt.
+func (o *Options) warnUnused() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, name := range slices.Sorted(maps.Keys(o.values)) {
+		if !o.used[name] {
+			fmt.Fprintf(os.Stderr, "option %s is not used by day %d\n", name, o.day)
+		}
+	}
+}
+
+// setFlag collects -set name=value flags. Values use the syntax of aoc.toml, except
+// that a string need not be quoted.
+type setFlag config.Table
+
+func (s setFlag) String() string {
+	var parts []string
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, s[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s setFlag) Set(arg string) error {
+	name, raw, ok := strings.Cut(arg, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value")
+	}
+	value, err := config.ParseValue(raw)
+	if err != nil {
+		value = raw
+	}
+	s[name] = value
+	return nil
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Command is one command of a day's interactive session, see Day.REPL.
+type Command struct {
+	Args string // synopsis of the arguments shown by help, e.g. "<id>..."
+	Help string // what the command does, in one line
+	// Run executes the command and writes its results to w. An error is shown to the
+	// user and the session goes on.
+	Run func(w io.Writer, args []string) error
+}
+
+// repl parses an input once with the day's REPL hook and runs the commands read from in
+// until the input ends or the user quits. Besides the day's own commands it offers
+// help, solve and quit.
+func repl(day Day, options *Options, filename string, in io.Reader, out io.Writer) {
+	if day.REPL == nil {
+		log.Fatalf("this day has no interactive commands")
+	}
+	if filename == "" {
+		log.Fatalf("-repl needs the input to explore, given with -input")
+	}
+
+	commands := day.REPL(&Run{Input: filename, Out: out, Options: options})
+	names := slices.Sorted(maps.Keys(commands))
+	fmt.Fprintf(out, "Loaded %s. Type help for the commands, quit to leave.\n", filename)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		name, args := fields[0], fields[1:]
+		switch name {
+		case "quit", "exit":
+			return
+		case "help":
+			for _, name := range names {
+				fmt.Fprintf(out, "  %-28s %s\n", strings.TrimSpace(name+" "+commands[name].Args), commands[name].Help)
+			}
+			fmt.Fprintf(out, "  %-28s %s\n", "solve <part>", "solve a part on the loaded input")
+			fmt.Fprintf(out, "  %-28s %s\n", "quit", "leave the session")
+		case "solve":
+			part, err := strconv.Atoi(strings.Join(args, ""))
+			if err != nil || part < 1 || part > len(day.Parts) {
+				fmt.Fprintf(out, "error: give a part from 1 to %d\n", len(day.Parts))
+				continue
+			}
+			fmt.Fprintf(out, "Part %d: %v\n", part, day.Parts[part-1].solve(&Run{Input: filename, Out: out, Options: options}))
+		default:
+			command, ok := commands[name]
+			if !ok {
+				fmt.Fprintf(out, "unknown command %q; type help for the commands\n", name)
+				continue
+			}
+			if err := command.Run(out, args); err != nil {
+				fmt.Fprintf(out, "error: %s\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,569 @@
+// Package runner provides the command line shared by every day's solution.
+//
+// A day describes its parts in main and hands them to Main:
+//
+//	func main() {
+//		runner.Main(runner.Day{
+//			Parts: []runner.Part{
+//				runner.NewPart(solveFirst, "input1.txt", "input2.txt"),
+//				runner.NewPart(solveSecond, "input1.txt", "input2.txt"),
+//			},
+//		})
+//	}
+//
+// Solvers must keep their state in local variables or per-call structs rather than
+// package variables, and print to Run.Out rather than standard output, so that several
+// inputs can be solved at the same time. The tests of each day check this with package
+// runnertest, which solves every input at once and compares what repeated runs print:
+//
+//	go test -race
+//
+// Settings come from the aoc.toml found in the working directory or a parent, see
+// package config. It sets the output format, where the default inputs live and the
+// named parameters solvers read from Run.Options. Flags override it, and -set
+// overrides a single parameter:
+//
+//	go run . -set connections=10
+//
+// An input whose puzzle uses other parameters, like an example, sets its own in its
+// options table in day.toml, see package registry.
+//
+// With -explain, days that record a trace print why they reached each answer.
+//
+// With -alloc the bytes and objects each solve allocates are printed after it, and
+// with -alloc-budget, or alloc_budget in aoc.toml, solves allocating more than the
+// budget are reported and make the run fail:
+//
+//	go run . -alloc-budget 64M
+//
+// The counts include what -play, -render and -explain record during the solve.
+//
+// Parts created with NewStrategies have several algorithms, say a quick heuristic and
+// an exact search, and pick one for each input from their cost hints. With -budget the
+// exact algorithm is used whenever it is expected to take no longer:
+//
+//	go run . -budget 2s
+//
+// Days that record animation frames in Run.Frames can be watched with -play, or
+// written to text files with -dump. With -render out.gif the frames become an
+// animated GIF, and with -render out.svg days that draw into Run.Drawing produce
+// an SVG picture.
+//
+// Puzzle inputs must not be published. Days with an Anonymize hook can rewrite a
+// real input into a fixture with the same structure but different answers:
+//
+//	go run . -input input2.txt -anonymize fixture.txt -seed 7
+//
+// writes fixture.txt and fixture.answers.txt with the answers solved on it, and fails
+// instead if an answer is the same as on the real input.
+//
+// Days with a Lint hook check an input before it is solved, listing every line that
+// does not have the expected shape:
+//
+//	go run . -lint -input input2.txt
+//
+// Days with a Generate hook write random inputs of any size, to check that solvers
+// streaming their input run in bounded memory:
+//
+//	go run . -generate big.txt -size 4G
+//
+// Alternatively an input can be committed encrypted, see package input:
+//
+//	AOC_INPUT_KEY=... go run . -input input2.txt -encrypt
+//
+// writes input2.txt.enc. Inputs that exist only encrypted are decrypted when the
+// passphrase is set and skipped otherwise.
+//
+// Days with a REPL hook can be explored interactively instead of editing main to
+// print intermediate results:
+//
+//	go run . -repl -input input2.txt
+//
+// With -json each answer is printed as an Answer object on its own line, for tools
+// such as aoc serve, and everything the solvers print goes to standard error.
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"aoc/anim"
+	"aoc/checked"
+	"aoc/config"
+	"aoc/input"
+	"aoc/registry"
+	"aoc/render"
+	"aoc/trace"
+)
+
+// Run describes a single invocation of a part on one input.
+type Run struct {
+	Input string // path of the puzzle input
+
+	// Out is where the solver prints its progress, with fmt.Fprintln(run.Out, ...) and
+	// the like: standard output, or standard error with -json, or nowhere with -quiet.
+	// Solvers never print to os.Stdout, so that runs at the same time keep apart.
+	Out io.Writer
+
+	Frames *anim.Recorder // animation frames; nil unless -play, -dump or -render out.gif is given
+
+	// Drawing is the picture written by -render out.svg, or nil when no SVG is wanted.
+	Drawing *render.SVG
+
+	// Options are the day's named parameters from aoc.toml and -set flags.
+	Options *Options
+
+	// Trace records why the solver reached its answer; nil unless -explain is given.
+	Trace *trace.Trace
+
+	budget   time.Duration // time the solve may take, from -budget; 0 for as fast as possible
+	strategy string        // name of the strategy that answered, for parts with several
+	estimate time.Duration // its estimated cost
+	unsure   []string      // strategies tried first that were not sure of their answer
+}
+
+// drawingWidth is the width of the pictures written by -render out.svg.
+const drawingWidth = 800
+
+// Part is one half of a day's puzzle.
+type Part struct {
+	Inputs []string // inputs solved when no -input flag is given
+	solve  func(*Run) any
+}
+
+// NewPart creates a part from a solver returning the answer, and the inputs it is solved on by default.
+func NewPart[T any](solve func(*Run) T, inputs ...string) Part {
+	return Part{
+		Inputs: inputs,
+		solve:  func(run *Run) any { return solve(run) },
+	}
+}
+
+// Solve solves the part on the input of run. Main calls it for each input, and tests
+// call it directly.
+func (p Part) Solve(run *Run) any {
+	return p.solve(run)
+}
+
+// Day lists the parts of a day's puzzle.
+type Day struct {
+	Parts []Part
+
+	// Anonymize, if set, rewrites the lines of an input into an input of the same
+	// shape whose answers differ, e.g. by relabelling names or shifting numbers.
+	// It must only draw randomness from rng so that a seed reproduces the fixture.
+	// options are the day's, for the input being rewritten.
+	Anonymize func(lines []string, rng *rand.Rand, options *Options) []string
+
+	// REPL, if set, parses the input of run once and returns the commands of an
+	// interactive session exploring it, keyed by name. Used by -repl.
+	REPL func(run *Run) map[string]Command
+
+	// Generate, if set, writes a random input of about size bytes to w, for checking
+	// that streaming solvers handle inputs larger than memory. Like Anonymize, it must
+	// only draw randomness from rng. Used by -generate.
+	Generate func(w io.Writer, size int64, rng *rand.Rand) error
+
+	// Lint, if set, checks the lines of an input against the shape the day expects
+	// and returns every problem found rather than stopping at the first. options are
+	// the day's, for the input being checked. Used by -lint.
+	Lint func(lines []string, options *Options) []Issue
+}
+
+// Main parses the command line and solves the requested parts.
+func Main(day Day) {
+	part := flag.Int("part", 0, "solve only this part (1 or 2); 0 solves all parts")
+	inputFile := flag.String("input", "", "solve this input instead of the default ones")
+	forceBig := flag.Bool("big", false, "force big-integer arithmetic to verify answers that could overflow")
+	quiet := flag.Bool("quiet", false, "only print the answers")
+	jsonOutput := flag.Bool("json", false, "print each answer as a JSON object and send the solvers' own output to standard error")
+	play := flag.Bool("play", false, "play the recorded animation frames in the terminal after each solve")
+	fps := flag.Int("fps", 10, "animation speed for -play in frames per second")
+	dump := flag.String("dump", "", "write the recorded animation frames as text files to this directory")
+	maxFrames := flag.Int("max-frames", 2000, "record at most this many animation frames per solve; 0 for no limit")
+	renderTo := flag.String("render", "", "write a picture of the solve to this .svg or .gif file")
+	anonymizeTo := flag.String("anonymize", "", "rewrite the -input file into this shareable fixture and solve it instead of solving normally")
+	seed := flag.Uint64("seed", 1, "random seed for -anonymize and -generate")
+	generateTo := flag.String("generate", "", "write a random input to this file and exit")
+	size := flag.String("size", "1M", "size of the input written by -generate, in bytes or with a K, M or G suffix")
+	interactive := flag.Bool("repl", false, "parse the -input file and explore it with the day's interactive commands")
+	lintInput := flag.Bool("lint", false, "check the -input file against the shape the day expects, report every problem and exit")
+	encryptInput := flag.Bool("encrypt", false, "write an encrypted copy of the -input file with the passphrase in $"+input.KeyEnv+" and exit")
+	configFile := flag.String("config", "", "read settings from this file instead of the "+config.FileName+" found in this or a parent directory")
+	sets := setFlag{}
+	flag.Var(sets, "set", "set a parameter of the day as name=value, overriding "+config.FileName+"; may be repeated")
+	var explain explainFlag
+	flag.Var(&explain, "explain", "explain each answer after it, as text or with -explain=json as part of the JSON answer")
+	explainLimit := flag.Int("explain-limit", 10000, "record at most this many explanation steps per solve; 0 for no limit")
+	reportAlloc := flag.Bool("alloc", false, "print the bytes and objects allocated by each solve")
+	budget := flag.Duration("budget", 0, "time a solve may take, for days choosing between a fast and an exact algorithm; 0 picks the fastest")
+	allocBudget := flag.String("alloc-budget", "", "report solves allocating more than this many bytes, with an optional K, M or G suffix, and fail; overrides alloc_budget in "+config.FileName)
+	flag.Parse()
+
+	settings := loadConfig(*configFile)
+	number := dayNumber()
+	options := newOptions(number, settings.Days[number], config.Table(sets))
+	meta := loadMeta()
+
+	// The output format of the configuration applies unless a flag chooses one
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if !explicit["quiet"] && !explicit["json"] {
+		*quiet = settings.Output == "quiet"
+		*jsonOutput = settings.Output == "json"
+	}
+	if explain == "json" {
+		*jsonOutput = true
+	}
+
+	if *part < 0 || *part > len(day.Parts) {
+		log.Fatalf("invalid part %d: the day has %d parts", *part, len(day.Parts))
+	}
+	checked.ForceBig(*forceBig)
+
+	if *allocBudget == "" {
+		*allocBudget = settings.AllocBudget
+	}
+	var allocBytes uint64
+	if *allocBudget != "" {
+		bytes, err := parseSize(*allocBudget)
+		if err != nil {
+			log.Fatalf("invalid allocation budget: %s", err)
+		}
+		allocBytes = uint64(bytes)
+	}
+	if *budget < 0 {
+		log.Fatalf("invalid budget %v", *budget)
+	}
+
+	if *interactive {
+		repl(day, inputOptions(meta, options, *inputFile), *inputFile, os.Stdin, os.Stdout)
+		return
+	}
+	if *lintInput {
+		lint(day, inputOptions(meta, options, *inputFile), *inputFile)
+		return
+	}
+	if *encryptInput {
+		encrypt(*inputFile)
+		return
+	}
+	if *generateTo != "" {
+		generate(day, *generateTo, *size, *seed)
+		return
+	}
+	if *anonymizeTo != "" {
+		anonymize(day, inputOptions(meta, options, *inputFile), *part, *inputFile, *anonymizeTo, *seed)
+		return
+	}
+
+	renderFormat := strings.ToLower(filepath.Ext(*renderTo))
+	if *renderTo != "" && renderFormat != ".svg" && renderFormat != ".gif" {
+		log.Fatalf("invalid -render file %s: use a .svg or .gif file", *renderTo)
+	}
+
+	// What the solvers print goes to standard error with -json, so that standard output
+	// holds only the answers, and nowhere with -quiet
+	var out io.Writer = os.Stdout
+	if *jsonOutput {
+		out = os.Stderr
+	} else if *quiet {
+		out = io.Discard
+	}
+
+	// Several solves each write their own picture, named after the part and input
+	solves := 0
+	for i, p := range day.Parts {
+		if *part != 0 && *part != i+1 {
+			continue
+		}
+		if *inputFile != "" {
+			solves++
+		} else {
+			solves += len(p.Inputs)
+		}
+	}
+
+	solved, overBudget := 0, 0
+	for i, p := range day.Parts {
+		if *part != 0 && *part != i+1 {
+			continue
+		}
+
+		var inputs []string
+		for _, name := range p.Inputs {
+			inputs = append(inputs, filepath.Join(settings.DayInputDir(number), name))
+		}
+		if *inputFile != "" {
+			inputs = []string{*inputFile}
+		}
+
+		for _, filename := range inputs {
+			if err := input.Available(filename); errors.Is(err, input.ErrNoKey) {
+				fmt.Fprintf(os.Stderr, "Part %d, %s: skipped, %s\n", i+1, filename, input.ErrNoKey)
+				continue
+			}
+
+			run := &Run{Input: filename, Out: out, Options: inputOptions(meta, options, filename), budget: *budget}
+			if *play || *dump != "" || renderFormat == ".gif" {
+				run.Frames = anim.NewRecorder(*maxFrames)
+			}
+			if renderFormat == ".svg" {
+				run.Drawing = render.NewSVG(drawingWidth)
+			}
+			if explain != "" {
+				run.Trace = trace.New(*explainLimit)
+			}
+
+			before := readAllocations()
+			start := time.Now()
+			answer := p.solve(run)
+			elapsed := time.Since(start)
+			allocated := readAllocations().since(before)
+			if choice := describeStrategy(run); choice != "" {
+				fmt.Fprintf(os.Stderr, "Part %d, %s: %s\n", i+1, filename, choice)
+			}
+			if *play || *dump != "" {
+				showFrames(run.Frames, i+1, filename, *play, *fps, *dump)
+			}
+			if *renderTo != "" {
+				target := *renderTo
+				if solves > 1 {
+					target = strings.TrimSuffix(target, filepath.Ext(target)) + "_" + solveName(i+1, filename) + filepath.Ext(target)
+				}
+				writePicture(run, i+1, target, *fps)
+			}
+			solved++
+			if *jsonOutput {
+				printJSON(os.Stdout, Answer{
+					Part:         i + 1,
+					Input:        filename,
+					Answer:       fmt.Sprint(answer),
+					Milliseconds: elapsed.Seconds() * 1000,
+					AllocBytes:   allocated.bytes,
+					AllocObjects: allocated.objects,
+					OverBudget:   allocBytes > 0 && allocated.bytes > allocBytes,
+					Strategy:     run.strategy,
+					Explanation:  run.Trace.Steps(),
+					Dropped:      run.Trace.Dropped(),
+				})
+			} else if *quiet {
+				fmt.Printf("Part %d, %s: %v\n", i+1, filename, answer)
+			}
+			if *reportAlloc && !*jsonOutput {
+				fmt.Printf("Part %d, %s: allocated %s\n", i+1, filename, allocated)
+			}
+			if allocBytes > 0 && allocated.bytes > allocBytes {
+				fmt.Fprintf(os.Stderr, "Part %d, %s: allocated %s, over the budget of %s\n",
+					i+1, filename, allocated, formatBytes(allocBytes))
+				overBudget++
+			}
+			if explain == "text" {
+				printExplanation(os.Stdout, i+1, filename, answer, run.Trace)
+			}
+		}
+
+		if !*quiet && !*jsonOutput {
+			fmt.Println()
+		}
+	}
+
+	if *part == 0 {
+		options.warnUnused()
+	}
+	if overBudget > 0 {
+		log.Fatalf("%d of %d solves allocated more than the budget of %s", overBudget, solved, formatBytes(allocBytes))
+	}
+}
+
+// LoadOptions returns the named parameters Main gives the day solved in the working
+// directory, from the aoc.toml found from it, so that tests solve the way Main does.
+func LoadOptions() *Options {
+	number := dayNumber()
+	return newOptions(number, loadConfig("").Days[number], nil)
+}
+
+// loadMeta returns the day.toml of the day in the working directory, or nil if it has
+// none.
+func loadMeta() *registry.Day {
+	meta, err := registry.Load(".")
+	if errors.Is(err, registry.ErrNoMetadata) {
+		return nil
+	}
+	if err != nil {
+		log.Fatalf("failed to load %s: %s", registry.MetaFile, err)
+	}
+	return meta
+}
+
+// inputOptions returns the options for solving the input file name, with those meta
+// sets for it.
+func inputOptions(meta *registry.Day, options *Options, name string) *Options {
+	if meta == nil {
+		return options
+	}
+	in, ok := meta.Input(name)
+	if !ok {
+		return options
+	}
+	return options.ForInput(in.Options)
+}
+
+// loadConfig loads the configuration file given with -config, or else the one found
+// from the working directory, or else the defaults.
+func loadConfig(path string) *config.Config {
+	if path == "" {
+		found, ok := config.Find(".")
+		if !ok {
+			return config.Default()
+		}
+		path = found
+	}
+	settings, err := config.Load(path)
+	if err != nil {
+		log.Fatalf("failed to load settings: %s", err)
+	}
+	return settings
+}
+
+// dayNumber returns the number of the day being solved, taken from the name of the
+// working directory since days run from their own directory, or 0 if it is not a number.
+func dayNumber() int {
+	dir, err := os.Getwd()
+	if err != nil {
+		return 0
+	}
+	number, err := strconv.Atoi(filepath.Base(dir))
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// showFrames plays or dumps the frames recorded while solving one input.
+func showFrames(frames *anim.Recorder, part int, input string, play bool, fps int, dump string) {
+	if len(frames.Frames()) == 0 {
+		fmt.Fprintf(os.Stderr, "Part %d, %s: no animation frames recorded\n", part, input)
+		return
+	}
+	if frames.Full() {
+		fmt.Fprintf(os.Stderr, "Part %d, %s: frame limit reached, later steps are not shown\n", part, input)
+	}
+
+	if play {
+		if err := anim.Play(os.Stdout, frames.Frames(), fps); err != nil {
+			log.Fatalf("failed to play animation: %s", err)
+		}
+	}
+	if dump != "" {
+		if err := anim.Dump(dump, solveName(part, input), frames.Frames()); err != nil {
+			log.Fatalf("failed to dump animation: %s", err)
+		}
+		fmt.Fprintf(os.Stderr, "Part %d, %s: wrote %d frames to %s\n", part, input, len(frames.Frames()), dump)
+	}
+}
+
+// writePicture writes the SVG drawing or the animation frames of a solve to a file.
+func writePicture(run *Run, part int, target string, fps int) {
+	if run.Drawing != nil && run.Drawing.Empty() || run.Frames != nil && len(run.Frames.Frames()) == 0 {
+		fmt.Fprintf(os.Stderr, "Part %d, %s: this part draws no %s picture\n", part, run.Input, filepath.Ext(target))
+		return
+	}
+
+	file, err := os.Create(target)
+	if err != nil {
+		log.Fatalf("failed to create picture: %s", err)
+	}
+	defer file.Close()
+
+	if run.Drawing != nil {
+		_, err = run.Drawing.WriteTo(file)
+	} else {
+		err = render.WriteGIF(file, run.Frames.Frames(), render.GIFOptions{Delay: time.Second / time.Duration(max(fps, 1))})
+	}
+	if err != nil {
+		log.Fatalf("failed to write picture: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "Part %d, %s: wrote %s\n", part, run.Input, target)
+}
+
+// Answer is one answer as printed by -json, one object per line.
+type Answer struct {
+	Part         int     `json:"part"`
+	Input        string  `json:"input"`
+	Answer       string  `json:"answer"` // formatted with fmt, so big numbers keep every digit
+	Milliseconds float64 `json:"duration_ms"`
+
+	// AllocBytes and AllocObjects count what the solve allocated on the heap, and
+	// OverBudget tells whether that is more than the allocation budget.
+	AllocBytes   uint64 `json:"alloc_bytes"`
+	AllocObjects uint64 `json:"alloc_objects"`
+	OverBudget   bool   `json:"over_budget,omitempty"`
+
+	// Strategy names the algorithm that answered, for parts with several.
+	Strategy string `json:"strategy,omitempty"`
+
+	// Explanation holds the steps recorded with -explain=json, and Dropped counts the
+	// steps left out past -explain-limit.
+	Explanation []*trace.Step `json:"explanation,omitempty"`
+	Dropped     int           `json:"explanation_dropped,omitempty"`
+}
+
+func printJSON(w io.Writer, answer Answer) {
+	if err := json.NewEncoder(w).Encode(answer); err != nil {
+		log.Fatalf("failed to write answer: %s", err)
+	}
+}
+
+// printExplanation prints the trace of one solve as text.
+func printExplanation(w io.Writer, part int, input string, answer any, t *trace.Trace) {
+	if len(t.Steps()) == 0 {
+		fmt.Fprintf(w, "Part %d, %s: this part records no explanation\n\n", part, input)
+		return
+	}
+	fmt.Fprintf(w, "Part %d, %s: %v because\n", part, input, answer)
+	if err := t.WriteText(w); err != nil {
+		log.Fatalf("failed to write explanation: %s", err)
+	}
+	fmt.Fprintln(w)
+}
+
+// explainFlag is the format of -explain, which may be given without one for text.
+type explainFlag string
+
+func (e *explainFlag) String() string {
+	return string(*e)
+}
+
+func (e *explainFlag) Set(s string) error {
+	switch s {
+	case "true", "text":
+		*e = "text"
+	case "json":
+		*e = "json"
+	case "false":
+		*e = ""
+	default:
+		return fmt.Errorf("unknown format %q, use text or json", s)
+	}
+	return nil
+}
+
+func (e *explainFlag) IsBoolFlag() bool {
+	return true
+}
+
+// solveName names the files written for one part and input, e.g. part1_input2.
+func solveName(part int, input string) string {
+	return fmt.Sprintf("part%d_%s", part, strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)))
+}
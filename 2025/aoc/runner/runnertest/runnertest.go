@@ -0,0 +1,205 @@
+// Package runnertest checks a day's solvers from its tests, against the answers listed
+// in the day.toml of its directory.
+//
+// A day describes itself in a function that main hands to runner.Main, and its test
+// file passes the same description here:
+//
+//	func TestAnswers(t *testing.T) {
+//		runnertest.Answers(t, newDay())
+//	}
+//
+//	func TestDeterministic(t *testing.T) {
+//		runnertest.Deterministic(t, newDay())
+//	}
+//
+// Run the tests with the race detector, since every input is solved at the same time:
+//
+//	go test -race
+package runnertest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"aoc/anim"
+	"aoc/input"
+	"aoc/registry"
+	"aoc/render"
+	"aoc/runner"
+	"aoc/trace"
+)
+
+// copies is how many times each input is solved at the same time.
+const copies = 2
+
+// Limits of what Deterministic records, the defaults of -max-frames and -explain-limit,
+// and the width of -render out.svg.
+const (
+	frameLimit   = 2000
+	traceLimit   = 10000
+	drawingWidth = 800
+)
+
+// Solve names one part solved on one input, by its name in day.toml.
+type Solve struct {
+	Part  int
+	Input string
+}
+
+// Answers solves every input of the day.toml in the working directory for each part
+// it lists an answer for, and checks the answers. Each solve is a parallel subtest
+// that solves its input several times at once, so that with -race solvers sharing
+// state are caught even when the tests run on a single CPU. Solves in skip are left
+// out, for inputs too slow to solve in a test.
+func Answers(t *testing.T, day runner.Day, skip ...Solve) {
+	t.Helper()
+	for _, s := range solves(t, day, skip) {
+		t.Run(fmt.Sprintf("part%d/%s", s.part, s.input.Name), func(t *testing.T) {
+			t.Parallel()
+			answers := make([]any, copies)
+			var wg sync.WaitGroup
+			for k := range answers {
+				run := s.run(t)
+				wg.Go(func() {
+					answers[k] = s.day.Parts[s.part-1].Solve(run)
+				})
+			}
+			wg.Wait()
+
+			for k, got := range answers {
+				if fmt.Sprint(got) != s.answer {
+					t.Errorf("solve %d of %d: got %v, want %s", k+1, copies, got, s.answer)
+				}
+			}
+		})
+	}
+}
+
+// Deterministic solves every input of the day.toml in the working directory for each
+// part it lists an answer for, several times at once, recording the frames, trace and
+// drawing of each run, and checks that the runs print and record the same bytes. Solves
+// in skip are left out, for inputs too slow to solve in a test.
+func Deterministic(t *testing.T, day runner.Day, skip ...Solve) {
+	t.Helper()
+	for _, s := range solves(t, day, skip) {
+		t.Run(fmt.Sprintf("part%d/%s", s.part, s.input.Name), func(t *testing.T) {
+			t.Parallel()
+			outputs := make([]string, copies)
+			var wg sync.WaitGroup
+			for k := range outputs {
+				run := s.run(t)
+				out := new(bytes.Buffer)
+				run.Out = out
+				run.Frames = anim.NewRecorder(frameLimit)
+				run.Trace = trace.New(traceLimit)
+				run.Drawing = render.NewSVG(drawingWidth)
+				wg.Go(func() {
+					answer := s.day.Parts[s.part-1].Solve(run)
+					outputs[k] = record(answer, out, run)
+				})
+			}
+			wg.Wait()
+
+			for k := 1; k < copies; k++ {
+				if line, first, other, ok := firstDifference(outputs[0], outputs[k]); !ok {
+					t.Errorf("solves 1 and %d differ at line %d:\n\t%q\n\t%q", k+1, line, first, other)
+				}
+			}
+		})
+	}
+}
+
+// record writes out everything a run produced: its answer, what it printed and the
+// trace, frames and drawing it recorded.
+func record(answer any, out *bytes.Buffer, run *runner.Run) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "answer: %v\n", answer)
+	sb.WriteString("-- output\n")
+	sb.Write(out.Bytes())
+	sb.WriteString("-- trace\n")
+	run.Trace.WriteText(&sb)
+	for i, frame := range run.Frames.Frames() {
+		fmt.Fprintf(&sb, "-- frame %d\n", i+1)
+		sb.WriteString(frame.Render(true))
+	}
+	sb.WriteString("-- drawing\n")
+	run.Drawing.WriteTo(&sb)
+	return sb.String()
+}
+
+// firstDifference compares two outputs line by line and returns the number of the first
+// line that differs with both versions of it, or ok if the outputs are identical.
+func firstDifference(a, b string) (line int, x, y string, ok bool) {
+	if a == b {
+		return 0, "", "", true
+	}
+	as, bs := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := range max(len(as), len(bs)) {
+		x, y = lineAt(as, i), lineAt(bs, i)
+		if x != y || i >= len(as) || i >= len(bs) {
+			return i + 1, x, y, false
+		}
+	}
+	panic("unreachable")
+}
+
+// lineAt returns line i of lines, or "" past the end.
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// solve is a part of the day to solve on an input, with the answer expected.
+type solve struct {
+	day     runner.Day
+	options *runner.Options
+	part    int
+	input   registry.Input
+	answer  string
+}
+
+// solves lists the solves with an answer in the day.toml of the working directory.
+func solves(t *testing.T, day runner.Day, skip []Solve) []solve {
+	t.Helper()
+	meta, err := registry.Load(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := runner.LoadOptions()
+
+	var result []solve
+	for _, in := range meta.Inputs {
+		for _, part := range slices.Sorted(maps.Keys(in.Answers)) {
+			if slices.Contains(skip, Solve{Part: part, Input: in.Name}) {
+				continue
+			}
+			if part > len(day.Parts) {
+				t.Fatalf("input %s has an answer for part %d, but the day has %d parts", in.Name, part, len(day.Parts))
+			}
+			result = append(result, solve{day: day, options: options.ForInput(in.Options), part: part, input: in, answer: in.Answers[part]})
+		}
+	}
+	if len(result) == 0 {
+		t.Fatal("day.toml lists no answers to check")
+	}
+	return result
+}
+
+// run returns a run of the solve that prints nowhere. Inputs committed only encrypted
+// skip the test when no passphrase is set.
+func (s solve) run(t *testing.T) *runner.Run {
+	t.Helper()
+	if err := input.Available(s.input.File); errors.Is(err, input.ErrNoKey) {
+		t.Skip(err)
+	}
+	return &runner.Run{Input: s.input.File, Out: io.Discard, Options: s.options}
+}
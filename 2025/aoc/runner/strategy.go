@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"aoc/anim"
+	"aoc/input"
+	"aoc/render"
+	"aoc/trace"
+)
+
+// Strategy is one algorithm solving a part, such as an exact search or a heuristic.
+type Strategy[T any] struct {
+	Name string
+
+	// Exact strategies are always right. Other strategies may be unsure of their
+	// answer, and the input is then solved again by an exact one.
+	Exact bool
+
+	// Cost estimates how long the strategy takes on an input of size bytes.
+	Cost func(size int64) time.Duration
+
+	// Solve returns the answer and whether the strategy is sure of it. Exact strategies
+	// always are.
+	Solve func(run *Run) (T, bool)
+}
+
+// NewStrategies creates a part solved by one of several strategies, at least one of
+// them exact, and the inputs it is solved on by default.
+//
+// For each input the cost of every strategy is estimated from the size of the input.
+// The cheapest exact strategy is used if it fits in the -budget. Otherwise the strategies
+// estimated to be cheaper are tried from the cheapest, until one is sure of its answer,
+// and the exact strategy is the fallback. Without a budget the cheaper strategies are
+// always tried first.
+func NewStrategies[T any](strategies []Strategy[T], inputs ...string) Part {
+	if !slices.ContainsFunc(strategies, func(s Strategy[T]) bool { return s.Exact }) {
+		panic("runner: NewStrategies needs an exact strategy")
+	}
+	return Part{
+		Inputs: inputs,
+		solve: func(run *Run) any {
+			size, err := input.Size(run.Input)
+			if err != nil {
+				log.Fatalf("failed to read input: %s", err)
+			}
+			costs := make([]time.Duration, len(strategies))
+			for i, s := range strategies {
+				costs[i] = s.Cost(size)
+			}
+
+			var answer T
+			for _, i := range plan(strategies, costs, run.budget) {
+				attempt := run.fresh()
+				var sure bool
+				answer, sure = strategies[i].Solve(attempt)
+				if sure || strategies[i].Exact {
+					run.Frames, run.Drawing, run.Trace = attempt.Frames, attempt.Drawing, attempt.Trace
+					run.strategy = strategies[i].Name
+					run.estimate = costs[i]
+					break
+				}
+				run.unsure = append(run.unsure, strategies[i].Name)
+			}
+			return answer
+		},
+	}
+}
+
+// plan returns the indices of the strategies to try in order, ending with the cheapest
+// exact one.
+func plan[T any](strategies []Strategy[T], costs []time.Duration, budget time.Duration) []int {
+	exact := -1
+	for i, s := range strategies {
+		if s.Exact && (exact == -1 || costs[i] < costs[exact]) {
+			exact = i
+		}
+	}
+	if budget > 0 && costs[exact] <= budget {
+		return []int{exact}
+	}
+
+	var order []int
+	for i, s := range strategies {
+		if !s.Exact && costs[i] < costs[exact] {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(costs[a], costs[b]) })
+	return append(order, exact)
+}
+
+// fresh returns a copy of run with new, empty recordings of the same kinds, for a
+// strategy whose answer may be thrown away along with what it recorded.
+func (run *Run) fresh() *Run {
+	attempt := *run
+	if run.Frames != nil {
+		attempt.Frames = anim.NewRecorder(run.Frames.Limit())
+	}
+	if run.Drawing != nil {
+		attempt.Drawing = render.NewSVG(drawingWidth)
+	}
+	if run.Trace != nil {
+		attempt.Trace = trace.New(run.Trace.Limit())
+	}
+	return &attempt
+}
+
+// describeStrategy tells which strategy answered a solve, and why, or "" for parts
+// with a single algorithm. The choice is only worth reporting with a budget or when
+// a strategy was unsure.
+func describeStrategy(run *Run) string {
+	if run.strategy == "" || run.budget == 0 && len(run.unsure) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "solved with %s", run.strategy)
+	if len(run.unsure) > 0 {
+		fmt.Fprintf(&sb, " after %s could not be sure", strings.Join(run.unsure, " and "))
+	}
+	if run.budget > 0 {
+		fmt.Fprintf(&sb, ", estimated at %v for a budget of %v", run.estimate, run.budget)
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package search
+
+import "container/heap"
+
+// AStar returns a goal reached with the lowest total cost, expanding states in order
+// of cost so far plus Heuristic. Step costs must not be negative. Without a Key the
+// same state may be expanded several times, so set one unless the space is a tree.
+func AStar[S any, K comparable](p Problem[S, K]) Result[S] {
+	var result Result[S]
+	estimate := func(s S) int {
+		if p.Heuristic == nil {
+			return 0
+		}
+		return p.Heuristic(s)
+	}
+
+	seen := newVisited(&p)
+	seen.improve(p.Start, 0)
+	nodes := []node[S]{{state: p.Start, parent: -1}}
+	queue := &frontier{}
+	queue.push(0, estimate(p.Start))
+
+	for queue.Len() > 0 {
+		result.Stats.MaxFrontier = max(result.Stats.MaxFrontier, queue.Len())
+		i := queue.pop()
+		current := nodes[i]
+
+		// Skip entries superseded by a cheaper path to the same state
+		if p.Key != nil && seen.seen[p.Key(current.state)] < current.cost {
+			continue
+		}
+
+		if p.Goal(current.state) {
+			result.Found = true
+			result.Path = pathTo(nodes, i)
+			result.Cost = current.cost
+			return result
+		}
+
+		result.Stats.Expanded++
+		for next := range p.Successors(current.state) {
+			result.Stats.Generated++
+			cost := current.cost + p.cost(current.state, next)
+			if !seen.improve(next, cost) {
+				result.Stats.Pruned++
+				continue
+			}
+			nodes = append(nodes, node[S]{state: next, parent: i, cost: cost})
+			queue.push(len(nodes)-1, cost+estimate(next))
+		}
+	}
+	return result
+}
+
+// frontier is a min-heap of node indices keyed by estimated total cost.
+type frontier struct {
+	items []frontierItem
+}
+
+type frontierItem struct {
+	node     int
+	priority int
+}
+
+func (f *frontier) push(node, priority int) {
+	heap.Push(f, frontierItem{node, priority})
+}
+
+func (f *frontier) pop() int {
+	return heap.Pop(f).(frontierItem).node
+}
+
+func (f *frontier) Len() int           { return len(f.items) }
+func (f *frontier) Less(i, j int) bool { return f.items[i].priority < f.items[j].priority }
+func (f *frontier) Swap(i, j int)      { f.items[i], f.items[j] = f.items[j], f.items[i] }
+func (f *frontier) Push(x any)         { f.items = append(f.items, x.(frontierItem)) }
+
+func (f *frontier) Pop() any {
+	last := f.items[len(f.items)-1]
+	f.items = f.items[:len(f.items)-1]
+	return last
+}
@@ -0,0 +1,252 @@
+// Package search provides generic state-space search: breadth-first search,
+// depth-first search, iterative deepening, A* and branch-and-bound.
+//
+// A search is described by a Problem. Every algorithm returns a Result holding the
+// path to the goal it found together with Stats about the work it did.
+package search
+
+import (
+	"iter"
+)
+
+// Problem describes a search space with states of type S. States whose Key is equal
+// are treated as the same state and visited once; K is the type of that key.
+// Use struct{} for K and leave Key nil when states need no deduplication.
+type Problem[S any, K comparable] struct {
+	// Start is the initial state.
+	Start S
+	// Successors yields the states reachable from s in one step.
+	//
+	// DFS, IDDFS and BranchAndBound explore each successor completely before asking
+	// for the next one, so with those algorithms Successors may hand out a shared
+	// mutable state and undo its change after yield returns.
+	Successors func(s S) iter.Seq[S]
+	// Goal reports whether s solves the problem.
+	Goal func(s S) bool
+	// Key identifies a state for deduplication. If nil, states are never deduplicated.
+	Key func(s S) K
+	// Cost returns the cost of the step from one state to the next. If nil, every step costs 1.
+	Cost func(from, to S) int
+	// Heuristic estimates the remaining cost from s to a goal for AStar. It must never
+	// overestimate. If nil, AStar behaves like Dijkstra's algorithm.
+	Heuristic func(s S) int
+	// Value scores a goal state for BranchAndBound, which maximizes it.
+	Value func(s S) int
+	// Bound returns an upper bound on the Value of every goal reachable from s.
+	// BranchAndBound skips states whose bound cannot beat the best goal found so far.
+	Bound func(s S) int
+}
+
+// Stats describes the work done by a search.
+type Stats struct {
+	Expanded    int // states whose successors were generated
+	Generated   int // successors produced, including duplicates
+	Pruned      int // states skipped as duplicates or by a bound
+	MaxFrontier int // largest number of states waiting at once: the queue, or the depth for DFS
+}
+
+// Result is the outcome of a search.
+type Result[S any] struct {
+	Found bool
+	// Path lists the states from Start to the goal, inclusive.
+	Path []S
+	// Cost is the total step cost of Path.
+	Cost int
+	// Value is the Value of the goal for BranchAndBound.
+	Value int
+	Stats Stats
+}
+
+// Goal returns the last state of the path. It must only be called when Found is true.
+func (r Result[S]) Goal() S {
+	return r.Path[len(r.Path)-1]
+}
+
+func (p *Problem[S, K]) cost(from, to S) int {
+	if p.Cost == nil {
+		return 1
+	}
+	return p.Cost(from, to)
+}
+
+// visited tracks the keys of states already seen. It does nothing when the problem has no Key.
+type visited[S any, K comparable] struct {
+	key  func(S) K
+	seen map[K]int
+}
+
+func newVisited[S any, K comparable](p *Problem[S, K]) *visited[S, K] {
+	return &visited[S, K]{key: p.Key, seen: map[K]int{}}
+}
+
+// improve records s with the given cost and reports whether it was not seen before
+// with a cost at most as low.
+func (v *visited[S, K]) improve(s S, cost int) bool {
+	if v.key == nil {
+		return true
+	}
+	k := v.key(s)
+	if old, ok := v.seen[k]; ok && old <= cost {
+		return false
+	}
+	v.seen[k] = cost
+	return true
+}
+
+// node is a state in a search tree that remembers how it was reached.
+type node[S any] struct {
+	state  S
+	parent int // index of the parent node, or -1 for the start
+	cost   int
+}
+
+func pathTo[S any](nodes []node[S], i int) []S {
+	var path []S
+	for ; i != -1; i = nodes[i].parent {
+		path = append(path, nodes[i].state)
+	}
+	for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+		path[l], path[r] = path[r], path[l]
+	}
+	return path
+}
+
+// BFS searches in breadth-first order and returns a goal reached with the fewest steps.
+func BFS[S any, K comparable](p Problem[S, K]) Result[S] {
+	var result Result[S]
+	seen := newVisited(&p)
+	seen.improve(p.Start, 0)
+
+	nodes := []node[S]{{state: p.Start, parent: -1}}
+	for head := 0; head < len(nodes); head++ {
+		current := nodes[head]
+		if p.Goal(current.state) {
+			result.Found = true
+			result.Path = pathTo(nodes, head)
+			result.Cost = current.cost
+			return result
+		}
+
+		result.Stats.Expanded++
+		for next := range p.Successors(current.state) {
+			result.Stats.Generated++
+			if !seen.improve(next, 0) {
+				result.Stats.Pruned++
+				continue
+			}
+			nodes = append(nodes, node[S]{state: next, parent: head, cost: current.cost + p.cost(current.state, next)})
+		}
+		result.Stats.MaxFrontier = max(result.Stats.MaxFrontier, len(nodes)-head-1)
+	}
+	return result
+}
+
+// DFS searches in depth-first order and returns the first goal it reaches,
+// which is not necessarily the closest one.
+func DFS[S any, K comparable](p Problem[S, K]) Result[S] {
+	d := depthFirst[S, K]{p: &p, seen: newVisited(&p), limit: -1}
+	d.seen.improve(p.Start, 0)
+	d.run(p.Start, 0)
+	return d.result
+}
+
+// IDDFS runs depth-limited depth-first searches with limits 0, 1, ..., maxDepth and
+// returns the first goal found, which is reached with the fewest steps. It uses far
+// less memory than BFS on wide search spaces. Stats accumulate over all iterations.
+func IDDFS[S any, K comparable](p Problem[S, K], maxDepth int) Result[S] {
+	var stats Stats
+	for limit := 0; limit <= maxDepth; limit++ {
+		d := depthFirst[S, K]{p: &p, seen: newVisited(&p), limit: limit}
+		d.seen.improve(p.Start, 0)
+		d.run(p.Start, 0)
+
+		stats.Expanded += d.result.Stats.Expanded
+		stats.Generated += d.result.Stats.Generated
+		stats.Pruned += d.result.Stats.Pruned
+		stats.MaxFrontier = max(stats.MaxFrontier, d.result.Stats.MaxFrontier)
+		if d.result.Found {
+			d.result.Stats = stats
+			return d.result
+		}
+	}
+	return Result[S]{Stats: stats}
+}
+
+// BranchAndBound explores the whole search space depth-first and returns the goal with
+// the highest Value. To minimize, negate the value and the bound. Goal states are not
+// expanded further.
+func BranchAndBound[S any, K comparable](p Problem[S, K]) Result[S] {
+	d := depthFirst[S, K]{p: &p, seen: newVisited(&p), limit: -1, optimize: true}
+	d.seen.improve(p.Start, 0)
+	d.run(p.Start, 0)
+	return d.result
+}
+
+// depthFirst holds the state of a recursive depth-first search.
+type depthFirst[S any, K comparable] struct {
+	p        *Problem[S, K]
+	seen     *visited[S, K]
+	limit    int  // maximum depth, or -1 for none
+	optimize bool // keep searching for a better Value instead of stopping at the first goal
+	path     []S
+	result   Result[S]
+}
+
+// run explores s and reports whether the search is finished.
+func (d *depthFirst[S, K]) run(s S, cost int) bool {
+	d.path = append(d.path, s)
+	defer func() { d.path = d.path[:len(d.path)-1] }()
+	depth := len(d.path) - 1
+	d.result.Stats.MaxFrontier = max(d.result.Stats.MaxFrontier, len(d.path))
+
+	if d.p.Goal(s) {
+		if !d.optimize {
+			d.record(cost)
+			return true
+		}
+		if value := d.p.Value(s); !d.result.Found || value > d.result.Value {
+			d.record(cost)
+			d.result.Value = value
+		}
+		return false
+	}
+
+	if d.optimize && d.result.Found && d.p.Bound != nil && d.p.Bound(s) <= d.result.Value {
+		d.result.Stats.Pruned++
+		return false
+	}
+	if depth == d.limit {
+		return false
+	}
+
+	d.result.Stats.Expanded++
+	for next := range d.p.Successors(s) {
+		d.result.Stats.Generated++
+		if !d.seen.improve(next, d.dedupeDepth(depth+1)) {
+			d.result.Stats.Pruned++
+			continue
+		}
+		if d.run(next, cost+d.p.cost(s, next)) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeDepth returns the depth used to deduplicate a state. Depth-limited searches may
+// reach a state again on a shorter path and must explore it again from there; other
+// searches visit every state once.
+func (d *depthFirst[S, K]) dedupeDepth(depth int) int {
+	if d.limit >= 0 {
+		return depth
+	}
+	return 0
+}
+
+// record stores the current path as the result. The path is copied because the
+// search keeps modifying it.
+func (d *depthFirst[S, K]) record(cost int) {
+	d.result.Found = true
+	d.result.Path = append([]S(nil), d.path...)
+	d.result.Cost = cost
+}
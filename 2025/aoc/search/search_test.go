@@ -0,0 +1,164 @@
+package search
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// graphProblem searches from s to g in a graph given as the successors of each vertex,
+// in the order they are yielded.
+func graphProblem(edges map[rune]string) Problem[rune, rune] {
+	return Problem[rune, rune]{
+		Start: 's',
+		Successors: func(v rune) iter.Seq[rune] {
+			return func(yield func(rune) bool) {
+				for _, w := range edges[v] {
+					if !yield(w) {
+						return
+					}
+				}
+			}
+		},
+		Goal: func(v rune) bool { return v == 'g' },
+		Key:  func(v rune) rune { return v },
+	}
+}
+
+func TestIDDFS(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    map[rune]string
+		maxDepth int
+		want     string // the path found, empty if none
+	}{
+		{"one step at the limit", map[rune]string{'s': "g"}, 1, "sg"},
+		{"chain", map[rune]string{'s': "a", 'a': "b", 'b': "g"}, 5, "sabg"},
+		{"depth too small", map[rune]string{'s': "a", 'a': "b", 'b': "g"}, 2, ""},
+		{"shortest after a longer branch", map[rune]string{'s': "ab", 'a': "c", 'c': "g", 'b': "g"}, 5, "sbg"},
+		// x is first reached at the depth limit through a, and must be explored again
+		// when s reaches it directly
+		{"state seen deeper first", map[rune]string{'s': "ax", 'a': "x", 'x': "g"}, 5, "sxg"},
+		{"cycle", map[rune]string{'s': "a", 'a': "sb", 'b': "ag"}, 5, "sabg"},
+		{"unreachable", map[rune]string{'s': "a", 'a': "s"}, 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := graphProblem(tt.edges)
+			got := IDDFS(p, tt.maxDepth)
+			if path := string(got.Path); path != tt.want || got.Found != (tt.want != "") {
+				t.Errorf("IDDFS() = %q, found %v, want %q", path, got.Found, tt.want)
+			}
+			if bfs := BFS(p); got.Found && got.Cost != bfs.Cost {
+				t.Errorf("IDDFS() cost = %d, BFS cost = %d", got.Cost, bfs.Cost)
+			}
+		})
+	}
+}
+
+// point is a cell of a weighted grid.
+type point struct{ x, y int }
+
+// gridProblem searches a grid of digits from the top left to the bottom right corner,
+// where entering a cell costs its digit.
+func gridProblem(rows []string) Problem[point, point] {
+	goal := point{len(rows[0]) - 1, len(rows) - 1}
+	return Problem[point, point]{
+		Start: point{0, 0},
+		Successors: func(p point) iter.Seq[point] {
+			return func(yield func(point) bool) {
+				for _, d := range []point{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+					q := point{p.x + d.x, p.y + d.y}
+					if q.x >= 0 && q.y >= 0 && q.x <= goal.x && q.y <= goal.y && !yield(q) {
+						return
+					}
+				}
+			}
+		},
+		Goal: func(p point) bool { return p == goal },
+		Key:  func(p point) point { return p },
+		Cost: func(_, to point) int { return int(rows[to.y][to.x] - '0') },
+		Heuristic: func(p point) int {
+			return goal.x - p.x + goal.y - p.y
+		},
+	}
+}
+
+// cheapest returns the lowest cost from the top left to the bottom right corner of the
+// grid, found by relaxing every cell until nothing improves.
+func cheapest(rows []string) int {
+	cost := make([][]int, len(rows))
+	for y := range cost {
+		cost[y] = slices.Repeat([]int{1 << 30}, len(rows[y]))
+	}
+	cost[0][0] = 0
+	for changed := true; changed; {
+		changed = false
+		for y := range rows {
+			for x := range rows[y] {
+				for _, d := range []point{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+					px, py := x+d.x, y+d.y
+					if py < 0 || py >= len(rows) || px < 0 || px >= len(rows[y]) {
+						continue
+					}
+					if c := cost[py][px] + int(rows[y][x]-'0'); c < cost[y][x] {
+						cost[y][x] = c
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return cost[len(rows)-1][len(rows[0])-1]
+}
+
+func TestAStar(t *testing.T) {
+	tests := []struct {
+		name string
+		grid string
+	}{
+		{"single cell", "5"},
+		{"uniform", "111\n111\n111"},
+		{"detour around a wall", "19999\n19111\n11191\n99991"},
+		{"cheap path leads backwards", "1911\n1919\n1119\n9991"},
+		{"greedy first step is worse", "1299\n1999\n1111"},
+		{"heuristic misleads", "11111\n99991\n11111\n19999\n11111"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := strings.Split(tt.grid, "\n")
+			want := cheapest(rows)
+
+			p := gridProblem(rows)
+			dijkstra := p
+			dijkstra.Heuristic = nil
+			for name, p := range map[string]Problem[point, point]{"with heuristic": p, "without heuristic": dijkstra} {
+				got := AStar(p)
+				if !got.Found || got.Cost != want {
+					t.Errorf("AStar() %s: cost = %d, found %v, want %d", name, got.Cost, got.Found, want)
+				}
+
+				// The path must be a walk of single steps whose costs add up to Cost
+				sum := 0
+				for i := 1; i < len(got.Path); i++ {
+					from, to := got.Path[i-1], got.Path[i]
+					if abs(to.x-from.x)+abs(to.y-from.y) != 1 {
+						t.Fatalf("AStar() %s: path steps from %v to %v", name, from, to)
+					}
+					sum += p.Cost(from, to)
+				}
+				if sum != got.Cost {
+					t.Errorf("AStar() %s: path costs %d, Cost = %d", name, sum, got.Cost)
+				}
+			}
+		})
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
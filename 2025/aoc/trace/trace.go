@@ -0,0 +1,121 @@
+// Package trace records why a solver reached its answer, as a tree of readable steps.
+//
+// A solver adds notes to the Trace it is given, grouping them in sections. Tracing is
+// off when the trace is nil, so solvers check Enabled before preparing costly notes:
+//
+//	if run.Trace.Enabled() {
+//		region := run.Trace.Section("Region %s fits", size)
+//		region.Note("present %d at %v", i, offset)
+//	}
+package trace
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Step is one entry of a trace, with the steps that explain it.
+type Step struct {
+	Text  string  `json:"text"`
+	Steps []*Step `json:"steps,omitempty"`
+}
+
+// Trace collects the steps of one section. A nil Trace records nothing, so solvers
+// can use it unconditionally. It is not safe for concurrent use.
+type Trace struct {
+	step   *Step // nil once the limit is reached
+	budget *budget
+}
+
+// budget is the number of steps a trace and all its sections may still record.
+type budget struct {
+	limit     int
+	remaining int
+	dropped   int
+}
+
+// New creates a trace that keeps at most limit steps, or any number if limit is 0.
+func New(limit int) *Trace {
+	return &Trace{step: &Step{}, budget: &budget{limit: limit, remaining: limit}}
+}
+
+// Enabled reports whether steps added now would be kept.
+func (t *Trace) Enabled() bool {
+	return t != nil && t.step != nil && (t.budget.limit == 0 || t.budget.remaining > 0)
+}
+
+// add appends a step, or counts it as dropped past the limit.
+func (t *Trace) add(text string) *Step {
+	if t == nil {
+		return nil
+	}
+	if !t.Enabled() {
+		t.budget.dropped++
+		return nil
+	}
+	t.budget.remaining--
+	step := &Step{Text: text}
+	t.step.Steps = append(t.step.Steps, step)
+	return step
+}
+
+// Note records a step.
+func (t *Trace) Note(format string, args ...any) {
+	t.add(fmt.Sprintf(format, args...))
+}
+
+// Section records a step and returns a trace for the steps explaining it.
+func (t *Trace) Section(format string, args ...any) *Trace {
+	if t == nil {
+		return nil
+	}
+	return &Trace{step: t.add(fmt.Sprintf(format, args...)), budget: t.budget}
+}
+
+// Steps returns the steps recorded at the top level.
+func (t *Trace) Steps() []*Step {
+	if t == nil || t.step == nil {
+		return nil
+	}
+	return t.step.Steps
+}
+
+// Dropped returns how many steps were not recorded because of the limit. Steps inside
+// sections that were dropped are not counted.
+func (t *Trace) Dropped() int {
+	if t == nil {
+		return 0
+	}
+	return t.budget.dropped
+}
+
+// Limit returns the most steps the trace keeps, 0 for no limit.
+func (t *Trace) Limit() int {
+	if t == nil {
+		return 0
+	}
+	return t.budget.limit
+}
+
+// WriteText writes the steps as an indented outline, one step per line.
+func (t *Trace) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	var write func(steps []*Step, depth int)
+	write = func(steps []*Step, depth int) {
+		for _, step := range steps {
+			for line := range strings.Lines(step.Text) {
+				sb.WriteString(strings.Repeat("  ", depth))
+				sb.WriteString(strings.TrimSuffix(line, "\n"))
+				sb.WriteByte('\n')
+			}
+			write(step.Steps, depth+1)
+		}
+	}
+	write(t.Steps(), 0)
+	if dropped := t.Dropped(); dropped > 0 {
+		fmt.Fprintf(&sb, "... and %d more steps past the limit\n", dropped)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}